@@ -88,6 +88,96 @@ func DecodeBatchRequest(data []byte) ([]*Request, error) {
 	return requests, nil
 }
 
+// BatchRequestElement is the decoding result of a single element of a batch request. Exactly one
+// of Request and Err is set.
+type BatchRequestElement struct {
+	// Request is the decoded request if the element is a valid Request object.
+	Request *Request
+
+	// Err is set if the element is not a valid Request object. Its code is InvalidRequest, or
+	// InvalidParams if only the params member is invalid.
+	Err *Error
+
+	// ID is the id of the element. For invalid elements it is extracted on a best-effort basis and
+	// is null if it could not be determined, as required by the JSON-RPC 2.0 specification.
+	ID ID
+}
+
+// IsValid returns true if the element decoded to a valid Request.
+func (e BatchRequestElement) IsValid() bool {
+	return e.Request != nil
+}
+
+// ErrorResponse returns the error response to send for an invalid element, or nil if the element
+// is valid.
+func (e BatchRequestElement) ErrorResponse() *Response {
+	if e.Err == nil {
+		return nil
+	}
+	return NewErrorResponse(e.ID, e.Err)
+}
+
+// DecodeBatchRequestElements parses a JSON-RPC batch request from a byte slice, decoding each
+// element independently. Unlike DecodeBatchRequest, an invalid element does not fail the whole
+// batch: it results in an element carrying an error and its best-effort id, so
+// that servers can answer it with an error response and still process the valid elements.
+// For example, the batch [1,2,3] yields three InvalidRequest elements with null ids.
+//
+// An error is returned only if the batch as a whole is invalid:
+// - Input is not valid JSON, which should be answered with a ParseError
+// - Input is not a JSON array or the array is empty, which should be answered with an
+// InvalidRequest
+func DecodeBatchRequestElements(data []byte) ([]BatchRequestElement, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, errors.New(errEmptyData)
+	}
+
+	var rawMessages []json.RawMessage
+	if err := getSonicAPI().Unmarshal(data, &rawMessages); err != nil {
+		return nil, fmt.Errorf("invalid batch format: %w", err)
+	}
+
+	if len(rawMessages) == 0 {
+		return nil, errors.New("batch request must contain at least one request")
+	}
+
+	elements := make([]BatchRequestElement, len(rawMessages))
+	for i, raw := range rawMessages {
+		elements[i] = decodeBatchRequestElement(raw)
+	}
+
+	return elements, nil
+}
+
+// decodeBatchRequestElement decodes a single batch element, turning decoding failures into an
+// error with the best-effort id of the element.
+func decodeBatchRequestElement(raw json.RawMessage) BatchRequestElement {
+	req, err := DecodeRequest(raw)
+	if err == nil {
+		return BatchRequestElement{Request: req, ID: req.ID}
+	}
+
+	rpcErr, id := classifyDecodeError(raw, err)
+	return BatchRequestElement{Err: rpcErr, ID: id}
+}
+
+// extractElementID returns the id member of a possibly invalid request object, or a null ID if
+// the element is not an object or its id is missing or invalid.
+func extractElementID(raw json.RawMessage) ID {
+	var aux struct {
+		ID json.RawMessage `json:"id"`
+	}
+	if err := getSonicAPI().Unmarshal(raw, &aux); err != nil {
+		return NullID()
+	}
+
+	id, err := ParseID(aux.ID)
+	if err != nil || id.IsAbsent() {
+		return NullID()
+	}
+	return id
+}
+
 // EncodeBatchRequest marshals a slice of JSON-RPC requests into a batch.
 // Returns an error if:
 // - Input slice is empty
@@ -108,6 +198,31 @@ func EncodeBatchRequest(reqs []*Request) ([]byte, error) {
 	return getSonicAPI().Marshal(reqs)
 }
 
+// WriteBatchRequest streams a slice of JSON-RPC requests to w as a batch, writing each request
+// with Request.WriteTo instead of marshaling the whole batch into memory first.
+// Returns an error if:
+// - Input slice is empty
+// - Any request fails validation
+// - Writing to w fails
+func WriteBatchRequest(w io.Writer, reqs []*Request) (int64, error) {
+	if len(reqs) == 0 {
+		return 0, errors.New("batch request must contain at least one request")
+	}
+
+	// Validate all requests first, so that nothing is written for an invalid batch
+	for i, req := range reqs {
+		if err := req.Validate(); err != nil {
+			return 0, fmt.Errorf("invalid request at index %d: %w", i, err)
+		}
+	}
+
+	writers := make([]io.WriterTo, len(reqs))
+	for i, req := range reqs {
+		writers[i] = req
+	}
+	return writeBatch(w, writers)
+}
+
 // DecodeBatchResponse parses a JSON-RPC batch response from a byte slice.
 // Returns an error if:
 // - Input is not a JSON array
@@ -161,6 +276,58 @@ func EncodeBatchResponse(resps []*Response) ([]byte, error) {
 	return getSonicAPI().Marshal(resps)
 }
 
+// WriteBatchResponse streams a slice of JSON-RPC responses to w as a batch, writing each response
+// with Response.WriteTo instead of marshaling the whole batch into memory first.
+// Returns an error if:
+// - Input slice is empty
+// - Any response fails validation
+// - Writing to w fails
+func WriteBatchResponse(w io.Writer, resps []*Response) (int64, error) {
+	if len(resps) == 0 {
+		return 0, errors.New("batch response must contain at least one response")
+	}
+
+	// Validate all responses first, so that nothing is written for an invalid batch
+	for i, resp := range resps {
+		if err := resp.Validate(); err != nil {
+			return 0, fmt.Errorf("invalid response at index %d: %w", i, err)
+		}
+	}
+
+	writers := make([]io.WriterTo, len(resps))
+	for i, resp := range resps {
+		writers[i] = resp
+	}
+	return writeBatch(w, writers)
+}
+
+// writeBatch writes the elements as a JSON array to w.
+func writeBatch(w io.Writer, elems []io.WriterTo) (int64, error) {
+	var total int64
+	if err := writeString(w, "[", &total); err != nil {
+		return total, err
+	}
+
+	for i, elem := range elems {
+		if i > 0 {
+			if err := writeString(w, ",", &total); err != nil {
+				return total, err
+			}
+		}
+		n, err := elem.WriteTo(w)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("failed to write element at index %d: %w", i, err)
+		}
+	}
+
+	if err := writeString(w, "]", &total); err != nil {
+		return total, err
+	}
+
+	return total, nil
+}
+
 // DecodeBatchRequestFromReader parses a JSON-RPC batch request from an io.Reader.
 func DecodeBatchRequestFromReader(r io.Reader, expectedSize int) ([]*Request, error) {
 	if r == nil {
@@ -192,8 +359,17 @@ func DecodeBatchResponseFromReader(r io.Reader, expectedSize int) ([]*Response,
 }
 
 // NewBatchRequest creates a batch of JSON-RPC requests from methods and params.
-// Each request receives an auto-generated ID.
+// Each request receives an ID from the package-wide IDGenerator that is unique within the batch.
 func NewBatchRequest(methods []string, params []any) ([]*Request, error) {
+	return NewBatchRequestWithGenerator(GetIDGenerator(), methods, params)
+}
+
+// NewBatchRequestWithGenerator creates a batch of JSON-RPC requests whose IDs are produced by gen.
+// IDs that collide with an earlier ID in the same batch are regenerated, and an error is returned
+// if gen keeps producing duplicates.
+func NewBatchRequestWithGenerator(
+	gen IDGenerator, methods []string, params []any,
+) ([]*Request, error) {
 	if len(methods) == 0 {
 		return nil, errors.New("batch must contain at least one method")
 	}
@@ -201,13 +377,18 @@ func NewBatchRequest(methods []string, params []any) ([]*Request, error) {
 		return nil, errors.New("params length must match methods length or be empty")
 	}
 
+	ids, err := uniqueBatchIDs(gen, len(methods))
+	if err != nil {
+		return nil, err
+	}
+
 	requests := make([]*Request, len(methods))
 	for i, method := range methods {
 		var p any
 		if i < len(params) {
 			p = params[i]
 		}
-		requests[i] = NewRequest(method, p)
+		requests[i] = NewRequestWithID(method, p, ids[i])
 	}
 
 	return requests, nil
@@ -0,0 +1,392 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"sync"
+)
+
+// DecodeBatchRequestSeq returns an iterator over the elements of a JSON-RPC batch request read from
+// r. The top-level array is tokenized incrementally and each element is decoded and yielded as soon
+// as it is complete, so only one element is held in memory at a time.
+//
+// An element that fails to decode as a Request is yielded as an error and iteration continues with
+// the next element. Malformed batch syntax, read errors and empty batches are yielded as a final
+// error, after which iteration stops.
+//
+// Example usage:
+//
+//	for req, err := range jsonrpc.DecodeBatchRequestSeq(body) {
+//		if err != nil {
+//			// Handle the error
+//			continue
+//		}
+//		// Process req
+//	}
+func DecodeBatchRequestSeq(r io.Reader) iter.Seq2[*Request, error] {
+	return func(yield func(*Request, error) bool) {
+		for i, elem := range scanBatch(r, yieldRequestError(yield)) {
+			req, err := DecodeRequest(elem)
+			if err != nil {
+				err = fmt.Errorf("invalid request at index %d: %w", i, err)
+			}
+			if !yield(req, err) {
+				return
+			}
+		}
+	}
+}
+
+// DecodeBatchResponseSeq returns an iterator over the elements of a JSON-RPC batch response read
+// from r. The top-level array is tokenized incrementally and each element is decoded and yielded as
+// soon as it is complete, so large batches can be processed and freed one element at a time.
+//
+// An element that fails to decode as a Response is yielded as an error and iteration continues
+// with the next element. Malformed batch syntax, read errors and empty batches are yielded as a
+// final error, after which iteration stops.
+func DecodeBatchResponseSeq(r io.Reader) iter.Seq2[*Response, error] {
+	return func(yield func(*Response, error) bool) {
+		for i, elem := range scanBatch(r, yieldResponseError(yield)) {
+			resp, err := DecodeResponse(elem)
+			if err != nil {
+				err = fmt.Errorf("invalid response at index %d: %w", i, err)
+			}
+			if !yield(resp, err) {
+				return
+			}
+		}
+	}
+}
+
+// yieldRequestError adapts a request yield function to report batch-level errors.
+func yieldRequestError(yield func(*Request, error) bool) func(error) {
+	return func(err error) { yield(nil, err) }
+}
+
+// yieldResponseError adapts a response yield function to report batch-level errors.
+func yieldResponseError(yield func(*Response, error) bool) func(error) {
+	return func(err error) { yield(nil, err) }
+}
+
+// scanBatch returns an iterator over the raw elements of the JSON array read from r, indexed by
+// their position. Each yielded slice is owned by the caller. Batch-level errors are passed to
+// onErr, after which iteration stops.
+func scanBatch(r io.Reader, onErr func(error)) iter.Seq2[int, []byte] {
+	return func(yield func(int, []byte) bool) {
+		if r == nil {
+			onErr(errors.New("cannot read from nil reader"))
+			return
+		}
+
+		s := newBatchScanner(r)
+		for i := 0; ; i++ {
+			elem, err := s.next()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				onErr(err)
+				return
+			}
+			if !yield(i, elem) {
+				return
+			}
+		}
+	}
+}
+
+// batchScanner splits a JSON array read from a stream into its top-level elements. It only
+// tracks nesting and string boundaries; the elements themselves are validated by the decoders.
+type batchScanner struct {
+	r       *bufio.Reader
+	buf     []byte
+	started bool
+	done    bool
+}
+
+// newBatchScanner returns a scanner reading from r, reusing r if it is already buffered.
+func newBatchScanner(r io.Reader) *batchScanner {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReaderSize(r, defaultChunkSize)
+	}
+	return &batchScanner{r: br}
+}
+
+// next returns the next element of the array, or io.EOF once the closing bracket is reached and
+// only whitespace follows it.
+func (s *batchScanner) next() ([]byte, error) {
+	if s.done {
+		return nil, io.EOF
+	}
+
+	c, err := s.skipWhitespace()
+	if err != nil {
+		return nil, s.unexpectedEOF(err)
+	}
+
+	if !s.started {
+		if c != '[' {
+			return nil, fmt.Errorf("invalid batch format: expected '[' but found %q", c)
+		}
+		s.started = true
+
+		if c, err = s.skipWhitespace(); err != nil {
+			return nil, s.unexpectedEOF(err)
+		}
+		if c == ']' {
+			return nil, errors.New("batch must contain at least one element")
+		}
+	} else {
+		switch c {
+		case ']':
+			s.done = true
+			return nil, s.expectEnd()
+		case ',':
+			if c, err = s.skipWhitespace(); err != nil {
+				return nil, s.unexpectedEOF(err)
+			}
+		default:
+			return nil, fmt.Errorf("invalid batch format: expected ',' or ']' but found %q", c)
+		}
+	}
+
+	if err := s.scanValue(c); err != nil {
+		return nil, err
+	}
+
+	// Copy out of the scratch buffer, since decoded elements may retain the bytes
+	elem := make([]byte, len(s.buf))
+	copy(elem, s.buf)
+	return elem, nil
+}
+
+// scanValue reads a single JSON value starting with first into the scratch buffer.
+func (s *batchScanner) scanValue(first byte) error {
+	s.buf = append(s.buf[:0], first)
+
+	switch first {
+	case '{', '[':
+		return s.scanComposite()
+	case '"':
+		return s.scanString()
+	case ',', ']', '}', ':':
+		return fmt.Errorf("invalid batch format: unexpected %q", first)
+	}
+
+	// Scalar literal: read until the next delimiter, which is left for the caller
+	for {
+		c, err := s.r.ReadByte()
+		if err != nil {
+			return s.unexpectedEOF(err)
+		}
+		if c == ',' || c == ']' || isJSONWhitespace(c) {
+			return s.r.UnreadByte()
+		}
+		s.buf = append(s.buf, c)
+	}
+}
+
+// scanComposite reads an object or array whose opening bracket is already buffered.
+func (s *batchScanner) scanComposite() error {
+	depth := 1
+	for depth > 0 {
+		c, err := s.r.ReadByte()
+		if err != nil {
+			return s.unexpectedEOF(err)
+		}
+		s.buf = append(s.buf, c)
+
+		switch c {
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+		case '"':
+			if err := s.scanString(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// scanString reads the remainder of a string whose opening quote is already buffered.
+func (s *batchScanner) scanString() error {
+	escaped := false
+	for {
+		c, err := s.r.ReadByte()
+		if err != nil {
+			return s.unexpectedEOF(err)
+		}
+		s.buf = append(s.buf, c)
+
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '"':
+			return nil
+		}
+	}
+}
+
+// skipWhitespace returns the next non-whitespace byte.
+func (s *batchScanner) skipWhitespace() (byte, error) {
+	for {
+		c, err := s.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if !isJSONWhitespace(c) {
+			return c, nil
+		}
+	}
+}
+
+// expectEnd verifies that only whitespace follows the closing bracket.
+func (s *batchScanner) expectEnd() error {
+	c, err := s.skipWhitespace()
+	if errors.Is(err, io.EOF) {
+		return io.EOF
+	}
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("invalid batch format: unexpected %q after end of batch", c)
+}
+
+// unexpectedEOF converts io.EOF into an error describing a truncated batch.
+func (s *batchScanner) unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		if !s.started {
+			return errors.New(errEmptyData)
+		}
+		return fmt.Errorf("invalid batch format: %w", io.ErrUnexpectedEOF)
+	}
+	return err
+}
+
+// isJSONWhitespace reports whether c is insignificant whitespace in JSON.
+func isJSONWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// BatchWriter streams a JSON-RPC batch response to an io.Writer one element at a time. The opening
+// bracket is written together with the first response and each subsequent response is written as
+// soon as it is passed to Write, so the first bytes go out before the whole batch is complete and
+// only one response needs to be held at a time.
+//
+// Write may be called concurrently, e.g. from goroutines executing batch items in parallel;
+// responses are written in the order the calls acquire the writer, which need not match the order
+// of the requests. If no response is written, e.g. because the batch only contained
+// notifications, Close writes nothing, as required by the JSON-RPC 2.0 specification.
+//
+// Example usage:
+//
+//	bw := jsonrpc.NewBatchWriter(w)
+//	for _, resp := range responses {
+//		if err := bw.Write(resp); err != nil {
+//			return err
+//		}
+//	}
+//	return bw.Close()
+type BatchWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	written int64
+	count   int
+	closed  bool
+	err     error
+}
+
+// NewBatchWriter returns a BatchWriter writing to w. The caller is responsible for flushing w if
+// it is buffered.
+func NewBatchWriter(w io.Writer) *BatchWriter {
+	return &BatchWriter{w: w}
+}
+
+// Write validates resp and appends it to the batch. An invalid response is rejected without
+// writing anything. A failed write leaves the output incomplete, so the error is returned by all
+// subsequent calls.
+func (bw *BatchWriter) Write(resp *Response) error {
+	if resp == nil {
+		return errors.New("cannot write nil response")
+	}
+	if err := resp.Validate(); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+
+	if bw.err != nil {
+		return bw.err
+	}
+	if bw.closed {
+		return errors.New("batch writer is closed")
+	}
+
+	sep := ","
+	if bw.count == 0 {
+		sep = "["
+	}
+	if err := writeString(bw.w, sep, &bw.written); err != nil {
+		bw.err = fmt.Errorf("failed to write batch: %w", err)
+		return bw.err
+	}
+
+	n, err := resp.WriteTo(bw.w)
+	bw.written += n
+	if err != nil {
+		bw.err = fmt.Errorf("failed to write element at index %d: %w", bw.count, err)
+		return bw.err
+	}
+	bw.count++
+
+	return nil
+}
+
+// Close terminates the batch by writing the closing bracket. If no responses were written,
+// nothing is written at all. Close does not close the underlying writer, and calling it more than
+// once has no further effect.
+func (bw *BatchWriter) Close() error {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+
+	if bw.err != nil {
+		return bw.err
+	}
+	if bw.closed {
+		return nil
+	}
+	bw.closed = true
+
+	if bw.count == 0 {
+		return nil
+	}
+	if err := writeString(bw.w, "]", &bw.written); err != nil {
+		bw.err = fmt.Errorf("failed to write batch: %w", err)
+		return bw.err
+	}
+
+	return nil
+}
+
+// Count returns the number of responses written so far.
+func (bw *BatchWriter) Count() int {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	return bw.count
+}
+
+// BytesWritten returns the number of bytes written to the underlying writer so far.
+func (bw *BatchWriter) BytesWritten() int64 {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	return bw.written
+}
@@ -0,0 +1,240 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func collectResponses(t *testing.T, r io.Reader) ([]*Response, []error) {
+	t.Helper()
+	var resps []*Response
+	var errs []error
+	for resp, err := range DecodeBatchResponseSeq(r) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		resps = append(resps, resp)
+	}
+	return resps, errs
+}
+
+func TestDecodeBatchResponseSeq(t *testing.T) {
+	t.Run("Yields each element", func(t *testing.T) {
+		data := ` [ {"jsonrpc":"2.0","id":1,"result":"a"},
+			{"jsonrpc":"2.0","id":"two","result":{"s":"x]}\"y","n":[1,2]}},
+			{"jsonrpc":"2.0","id":3,"error":{"code":-32601,"message":"Method not found"}} ] `
+
+		resps, errs := collectResponses(t, strings.NewReader(data))
+		require.Empty(t, errs)
+		require.Len(t, resps, 3)
+
+		assert.Equal(t, Int64ID(1), resps[0].ID())
+		assert.Equal(t, StringID("two"), resps[1].ID())
+		assert.JSONEq(t, `{"s":"x]}\"y","n":[1,2]}`, string(resps[1].RawResult()))
+		require.NotNil(t, resps[2].Err())
+		assert.Equal(t, -32601, resps[2].Err().Code)
+	})
+
+	t.Run("Reads incrementally", func(t *testing.T) {
+		data := `[{"jsonrpc":"2.0","id":1,"result":true},{"jsonrpc":"2.0","id":2,"result":false}]`
+
+		resps, errs := collectResponses(t, iotest.OneByteReader(strings.NewReader(data)))
+		require.Empty(t, errs)
+		require.Len(t, resps, 2)
+	})
+
+	t.Run("Yields before the batch is complete", func(t *testing.T) {
+		pr, pw := io.Pipe()
+		go func() {
+			_, _ = pw.Write([]byte(`[{"jsonrpc":"2.0","id":1,"result":1},`))
+		}()
+
+		for resp, err := range DecodeBatchResponseSeq(pr) {
+			require.NoError(t, err)
+			assert.Equal(t, Int64ID(1), resp.ID())
+			break
+		}
+		_ = pw.Close()
+	})
+
+	t.Run("Invalid element continues iteration", func(t *testing.T) {
+		data := `[{"jsonrpc":"2.0","id":1,"result":1},{"id":2},{"jsonrpc":"2.0","id":3,"result":3}]`
+
+		resps, errs := collectResponses(t, strings.NewReader(data))
+		require.Len(t, errs, 1)
+		assert.Contains(t, errs[0].Error(), "invalid response at index 1")
+		require.Len(t, resps, 2)
+		assert.Equal(t, Int64ID(3), resps[1].ID())
+	})
+
+	t.Run("Batch-level errors", func(t *testing.T) {
+		cases := []struct {
+			name string
+			data string
+			msg  string
+		}{
+			{name: "Empty input", data: "  ", msg: errEmptyData},
+			{name: "Not an array", data: `{"jsonrpc":"2.0"}`, msg: "expected '['"},
+			{name: "Empty array", data: `[ ]`, msg: "at least one element"},
+			{name: "Truncated", data: `[{"jsonrpc":"2.0","id":1`, msg: "unexpected EOF"},
+			{name: "Missing comma", data: `[{"a":1} {"b":2}]`, msg: "expected ',' or ']'"},
+			{name: "Trailing data", data: `[{"jsonrpc":"2.0","id":1,"result":1}] x`, msg: "after end"},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, errs := collectResponses(t, strings.NewReader(tc.data))
+				require.NotEmpty(t, errs)
+				assert.Contains(t, errs[len(errs)-1].Error(), tc.msg)
+			})
+		}
+	})
+
+	t.Run("Read error", func(t *testing.T) {
+		readErr := errors.New("connection reset")
+		r := io.MultiReader(strings.NewReader(`[{"jsonrpc":"2.0",`), iotest.ErrReader(readErr))
+
+		_, errs := collectResponses(t, r)
+		require.Len(t, errs, 1)
+		assert.ErrorIs(t, errs[0], readErr)
+	})
+
+	t.Run("Nil reader", func(t *testing.T) {
+		_, errs := collectResponses(t, nil)
+		require.Len(t, errs, 1)
+	})
+}
+
+func TestDecodeBatchRequestSeq(t *testing.T) {
+	t.Run("Yields each element", func(t *testing.T) {
+		data := `[{"jsonrpc":"2.0","id":1,"method":"sum","params":[1,2]},
+			{"jsonrpc":"2.0","method":"notify"}]`
+
+		var reqs []*Request
+		for req, err := range DecodeBatchRequestSeq(strings.NewReader(data)) {
+			require.NoError(t, err)
+			reqs = append(reqs, req)
+		}
+
+		require.Len(t, reqs, 2)
+		assert.Equal(t, "sum", reqs[0].Method)
+		assert.JSONEq(t, `[1,2]`, string(reqs[0].RawParams()))
+		assert.True(t, reqs[1].IsNotification())
+	})
+
+	t.Run("Invalid element and early stop", func(t *testing.T) {
+		data := `[{"jsonrpc":"2.0","id":1},{"jsonrpc":"2.0","id":2,"method":"m"},{"bad"`
+
+		var errs []error
+		for req, err := range DecodeBatchRequestSeq(strings.NewReader(data)) {
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			assert.Equal(t, "m", req.Method)
+			break
+		}
+
+		require.Len(t, errs, 1)
+		assert.Contains(t, errs[0].Error(), "invalid request at index 0")
+	})
+}
+
+func TestBatchWriter(t *testing.T) {
+	t.Run("Writes a valid batch", func(t *testing.T) {
+		var buf bytes.Buffer
+		bw := NewBatchWriter(&buf)
+
+		resp1, err := NewResponse(1, "a")
+		require.NoError(t, err)
+		require.NoError(t, bw.Write(resp1))
+		assert.Equal(t, `[`, buf.String()[:1], "first element is written immediately")
+
+		require.NoError(t, bw.Write(NewErrorResponse(2, &Error{Code: -32601, Message: "nope"})))
+		require.NoError(t, bw.Close())
+
+		assert.JSONEq(t, `[
+			{"jsonrpc":"2.0","id":1,"result":"a"},
+			{"jsonrpc":"2.0","id":2,"error":{"code":-32601,"message":"nope"}}
+		]`, buf.String())
+		assert.Equal(t, 2, bw.Count())
+		assert.Equal(t, int64(buf.Len()), bw.BytesWritten())
+
+		resps, err := DecodeBatchResponse(buf.Bytes())
+		require.NoError(t, err)
+		assert.Len(t, resps, 2)
+	})
+
+	t.Run("No responses writes nothing", func(t *testing.T) {
+		var buf bytes.Buffer
+		bw := NewBatchWriter(&buf)
+
+		require.NoError(t, bw.Close())
+		assert.Empty(t, buf.Bytes())
+		assert.Zero(t, bw.BytesWritten())
+	})
+
+	t.Run("Concurrent writes", func(t *testing.T) {
+		var buf bytes.Buffer
+		bw := NewBatchWriter(&buf)
+
+		const n = 50
+		var wg sync.WaitGroup
+		for i := range n {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := NewResponse(i, i)
+				assert.NoError(t, err)
+				assert.NoError(t, bw.Write(resp))
+			}()
+		}
+		wg.Wait()
+		require.NoError(t, bw.Close())
+
+		var decoded []json.RawMessage
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+		assert.Len(t, decoded, n)
+	})
+
+	t.Run("Invalid response is rejected without writing", func(t *testing.T) {
+		var buf bytes.Buffer
+		bw := NewBatchWriter(&buf)
+
+		assert.Error(t, bw.Write(nil))
+		assert.Error(t, bw.Write(&Response{}))
+		assert.Empty(t, buf.Bytes())
+		assert.Zero(t, bw.Count())
+	})
+
+	t.Run("Write after close", func(t *testing.T) {
+		bw := NewBatchWriter(io.Discard)
+		require.NoError(t, bw.Close())
+		require.NoError(t, bw.Close())
+
+		resp, err := NewResponse(1, true)
+		require.NoError(t, err)
+		assert.ErrorContains(t, bw.Write(resp), "closed")
+	})
+
+	t.Run("Write error is sticky", func(t *testing.T) {
+		bw := NewBatchWriter(errWriter{})
+
+		resp, err := NewResponse(1, true)
+		require.NoError(t, err)
+		first := bw.Write(resp)
+		require.Error(t, first)
+		assert.Equal(t, first, bw.Write(resp))
+		assert.Equal(t, first, bw.Close())
+	})
+}
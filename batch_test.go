@@ -20,8 +20,8 @@ func TestDecodeBatchRequest(t *testing.T) {
 		assert.Len(t, reqs, 2)
 		assert.Equal(t, "sum", reqs[0].Method)
 		assert.Equal(t, "subtract", reqs[1].Method)
-		assert.Equal(t, int64(1), reqs[0].ID)
-		assert.Equal(t, int64(2), reqs[1].ID)
+		assert.Equal(t, Int64ID(1), reqs[0].ID)
+		assert.Equal(t, Int64ID(2), reqs[1].ID)
 	})
 
 	t.Run("Empty batch returns error", func(t *testing.T) {
@@ -76,12 +76,81 @@ func TestDecodeBatchRequest(t *testing.T) {
 		reqs, err := DecodeBatchRequest(data)
 		require.NoError(t, err)
 		assert.Len(t, reqs, 3)
-		assert.Equal(t, "abc", reqs[0].ID)
-		assert.Equal(t, int64(123), reqs[1].ID)
-		assert.Equal(t, 45.67, reqs[2].ID)
+		assert.Equal(t, StringID("abc"), reqs[0].ID)
+		assert.Equal(t, Int64ID(123), reqs[1].ID)
+		assert.Equal(t, Float64ID(45.67), reqs[2].ID)
 	})
 }
 
+func TestDecodeBatchRequestElements(t *testing.T) {
+	t.Run("Mixed valid and invalid elements", func(t *testing.T) {
+		data := []byte(`[
+			{"jsonrpc":"2.0","id":1,"method":"sum","params":[1,2]},
+			{"jsonrpc":"2.0","method":"notify_hello","params":[7]},
+			{"foo":"boo"},
+			{"jsonrpc":"2.0","id":"5","method":1},
+			{"jsonrpc":"1.0","id":7,"method":"m"}
+		]`)
+		elems, err := DecodeBatchRequestElements(data)
+		require.NoError(t, err)
+		require.Len(t, elems, 5)
+
+		assert.True(t, elems[0].IsValid())
+		assert.Equal(t, "sum", elems[0].Request.Method)
+		assert.Equal(t, Int64ID(1), elems[0].ID)
+		assert.Nil(t, elems[0].ErrorResponse())
+
+		assert.True(t, elems[1].IsValid())
+		assert.True(t, elems[1].Request.IsNotification())
+
+		assert.False(t, elems[2].IsValid())
+		require.NotNil(t, elems[2].Err)
+		assert.Equal(t, InvalidRequest, elems[2].Err.Code)
+		assert.True(t, elems[2].ID.IsNull())
+
+		assert.Equal(t, StringID("5"), elems[3].ID)
+		assert.Equal(t, Int64ID(7), elems[4].ID)
+	})
+
+	t.Run("Spec example with non-object elements", func(t *testing.T) {
+		elems, err := DecodeBatchRequestElements([]byte(`[1,2,3]`))
+		require.NoError(t, err)
+		require.Len(t, elems, 3)
+
+		for _, elem := range elems {
+			resp := elem.ErrorResponse()
+			require.NotNil(t, resp)
+			data, err := resp.MarshalJSON()
+			require.NoError(t, err)
+			assert.JSONEq(t, `{"jsonrpc":"2.0","id":null,"error":{
+				"code":-32600,"message":"Invalid Request","data":`+
+				mustMarshalString(t, elem.Err.Data)+`}}`, string(data))
+		}
+	})
+
+	t.Run("Invalid id falls back to null", func(t *testing.T) {
+		elems, err := DecodeBatchRequestElements([]byte(`[{"jsonrpc":"2.0","id":{},"method":"m"}]`))
+		require.NoError(t, err)
+		require.Len(t, elems, 1)
+		assert.False(t, elems[0].IsValid())
+		assert.True(t, elems[0].ID.IsNull())
+	})
+
+	t.Run("Batch-level errors", func(t *testing.T) {
+		for _, data := range []string{``, `[`, `[]`, `{"jsonrpc":"2.0","id":1,"method":"m"}`} {
+			_, err := DecodeBatchRequestElements([]byte(data))
+			assert.Error(t, err, data)
+		}
+	})
+}
+
+func mustMarshalString(t *testing.T, v any) string {
+	t.Helper()
+	s, ok := v.(string)
+	require.True(t, ok)
+	return string(appendJSONString(nil, s))
+}
+
 func TestEncodeBatchRequest(t *testing.T) {
 	t.Run("Valid batch encoding", func(t *testing.T) {
 		reqs := []*Request{
@@ -221,7 +290,7 @@ func TestEncodeBatchResponse(t *testing.T) {
 
 		resps := []*Response{
 			validResp,
-			{jsonrpc: "1.0", id: int64(2)}, // Invalid version
+			{jsonrpc: "1.0", id: Int64ID(2)}, // Invalid version
 		}
 		_, err = EncodeBatchResponse(resps)
 		require.Error(t, err)
@@ -307,8 +376,8 @@ func TestNewBatchRequest(t *testing.T) {
 		assert.Len(t, reqs, 2)
 		assert.Equal(t, "sum", reqs[0].Method)
 		assert.Equal(t, "subtract", reqs[1].Method)
-		assert.NotNil(t, reqs[0].ID)
-		assert.NotNil(t, reqs[1].ID)
+		assert.False(t, reqs[0].ID.IsAbsent())
+		assert.False(t, reqs[1].ID.IsAbsent())
 	})
 
 	t.Run("Valid batch without params", func(t *testing.T) {
@@ -316,8 +385,8 @@ func TestNewBatchRequest(t *testing.T) {
 		reqs, err := NewBatchRequest(methods, nil)
 		require.NoError(t, err)
 		assert.Len(t, reqs, 2)
-		assert.Nil(t, reqs[0].Params)
-		assert.Nil(t, reqs[1].Params)
+		assert.Nil(t, reqs[0].Params())
+		assert.Nil(t, reqs[1].Params())
 	})
 
 	t.Run("Empty methods returns error", func(t *testing.T) {
@@ -419,9 +488,9 @@ func TestBatchWithMixedIDTypes(t *testing.T) {
 		reqs, err := DecodeBatchRequest(data)
 		require.NoError(t, err)
 		assert.Len(t, reqs, 3)
-		assert.Equal(t, "string-id", reqs[0].ID)
-		assert.Equal(t, int64(42), reqs[1].ID)
-		assert.Equal(t, 3.14, reqs[2].ID)
+		assert.Equal(t, StringID("string-id"), reqs[0].ID)
+		assert.Equal(t, Int64ID(42), reqs[1].ID)
+		assert.Equal(t, Float64ID(3.14), reqs[2].ID)
 	})
 }
 
@@ -483,7 +552,7 @@ func TestBatchWithDeeplyNestedParams(t *testing.T) {
 		reqs, err := DecodeBatchRequest(data)
 		require.NoError(t, err)
 		assert.Len(t, reqs, 1)
-		assert.NotNil(t, reqs[0].Params)
+		assert.NotNil(t, reqs[0].Params())
 	})
 }
 
@@ -503,7 +572,7 @@ func TestBatchRoundTrip(t *testing.T) {
 		assert.Len(t, decoded, 3)
 		assert.Equal(t, original[0].Method, decoded[0].Method)
 		assert.True(t, decoded[1].IsNotification())
-		assert.Equal(t, "custom-id", decoded[2].ID)
+		assert.Equal(t, StringID("custom-id"), decoded[2].ID)
 	})
 
 	t.Run("Response batch round-trip", func(t *testing.T) {
@@ -529,3 +598,73 @@ func TestBatchRoundTrip(t *testing.T) {
 		assert.Equal(t, InvalidRequest, decoded[1].Err().Code)
 	})
 }
+
+func TestWriteBatchRequest(t *testing.T) {
+	t.Run("Valid batch", func(t *testing.T) {
+		reqs := []*Request{
+			NewRequestWithID("sum", []int{1, 2}, int64(1)),
+			NewNotification("notify", nil),
+		}
+
+		var buf bytes.Buffer
+		n, err := WriteBatchRequest(&buf, reqs)
+		require.NoError(t, err)
+		assert.Equal(t, int64(buf.Len()), n)
+
+		encoded, err := EncodeBatchRequest(reqs)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(encoded), buf.String())
+	})
+
+	t.Run("Empty batch returns error", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := WriteBatchRequest(&buf, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("Invalid request writes nothing", func(t *testing.T) {
+		reqs := []*Request{
+			NewRequestWithID("sum", []int{1, 2}, int64(1)),
+			{JSONRPC: "2.0"},
+		}
+
+		var buf bytes.Buffer
+		_, err := WriteBatchRequest(&buf, reqs)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "index 1")
+		assert.Zero(t, buf.Len())
+	})
+}
+
+func TestWriteBatchResponse(t *testing.T) {
+	t.Run("Valid batch", func(t *testing.T) {
+		resp1, err := NewResponse(int64(1), 3)
+		require.NoError(t, err)
+		resp2 := NewErrorResponse(int64(2), &Error{Code: MethodNotFound, Message: "not found"})
+
+		var buf bytes.Buffer
+		n, err := WriteBatchResponse(&buf, []*Response{resp1, resp2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(buf.Len()), n)
+
+		resps, err := DecodeBatchResponse(buf.Bytes())
+		require.NoError(t, err)
+		require.Len(t, resps, 2)
+		assert.True(t, resp1.Equals(resps[0]))
+		assert.True(t, resp2.Equals(resps[1]))
+	})
+
+	t.Run("Empty batch returns error", func(t *testing.T) {
+		var buf bytes.Buffer
+		_, err := WriteBatchResponse(&buf, []*Response{})
+		require.Error(t, err)
+	})
+
+	t.Run("Writer error", func(t *testing.T) {
+		resp, err := NewResponse(int64(1), 3)
+		require.NoError(t, err)
+
+		_, err = WriteBatchResponse(errWriter{}, []*Response{resp})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,326 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultBatchWindow is the default time a Batcher waits for more calls before sending a batch.
+	DefaultBatchWindow = 2 * time.Millisecond
+
+	// DefaultBatchMaxItems is the default maximum number of requests in a batch of a Batcher.
+	DefaultBatchMaxItems = 100
+
+	// DefaultBatchMaxBytes is the default maximum approximate size of a batch of a Batcher.
+	DefaultBatchMaxBytes = 1 << 20 // 1 MiB
+)
+
+// BatcherConfig configures a Batcher.
+type BatcherConfig struct {
+	// Upstream receives the batches. With an HTTPUpstream, each batch is encoded with
+	// EncodeBatchRequest and sent in a single HTTP request. Required.
+	Upstream Upstream
+
+	// Window is the time a batch stays open for more calls after its first call. Defaults to
+	// DefaultBatchWindow.
+	Window time.Duration
+
+	// MaxItems is the number of requests after which a batch is sent without waiting for the
+	// window to end. Defaults to DefaultBatchMaxItems.
+	MaxItems int
+
+	// MaxBytes is the approximate size in bytes after which a batch is sent without waiting for
+	// the window to end. A request that would make a batch exceed it starts a new batch. Defaults
+	// to DefaultBatchMaxBytes.
+	MaxBytes int
+
+	// IDGenerator generates the IDs the requests are sent with. Defaults to a sequential generator
+	// starting at 1.
+	IDGenerator IDGenerator
+}
+
+// Batcher is an Upstream that collects concurrent calls into batches. Calls arriving within the
+// window of a batch are sent together as a single batch, and each caller receives the response
+// to its own request. A batch is sent early once it reaches MaxItems requests or MaxBytes bytes.
+//
+// Since concurrent callers may use the same request IDs, requests are sent with IDs from the
+// IDGenerator, and responses are returned with the ID of the request. A caller whose request is
+// not answered in the batch response receives an error, as do all callers of a batch that fails.
+// Notifications are sent in batches as well, and their calls return once the batch is sent.
+//
+// The upstream call of a batch is canceled once all of its callers have given up. A Batcher is
+// safe for concurrent use.
+//
+// Example usage:
+//
+//	batcher, err := jsonrpc.NewBatcher(jsonrpc.BatcherConfig{
+//		Upstream: upstream,
+//		Window:   5 * time.Millisecond,
+//		MaxItems: 50,
+//	})
+//	resp, err := batcher.Call(ctx, req)
+type Batcher struct {
+	upstream Upstream
+	window   time.Duration
+	maxItems int
+	maxBytes int
+	ids      IDGenerator
+
+	mutex   sync.Mutex
+	pending *pendingBatch
+}
+
+// pendingBatch is a batch collecting calls.
+type pendingBatch struct {
+	calls []*batchedCall
+	size  int
+	timer *time.Timer
+}
+
+// batchedCall is a call waiting for its batch.
+type batchedCall struct {
+	ctx  context.Context
+	req  *Request
+	done chan struct{}
+
+	// Set before done is closed
+	resp *Response
+	err  error
+}
+
+// NewBatcher creates a Batcher with the given configuration.
+func NewBatcher(config BatcherConfig) (*Batcher, error) {
+	if config.Upstream == nil {
+		return nil, errors.New("upstream cannot be nil")
+	}
+
+	batcher := &Batcher{
+		upstream: config.Upstream,
+		window:   positiveOr(config.Window, DefaultBatchWindow),
+		maxItems: positiveOr(config.MaxItems, DefaultBatchMaxItems),
+		maxBytes: positiveOr(config.MaxBytes, DefaultBatchMaxBytes),
+		ids:      config.IDGenerator,
+	}
+	if batcher.ids == nil {
+		batcher.ids = NewSequentialIDGenerator(1)
+	}
+
+	return batcher, nil
+}
+
+// Call adds the request to the current batch and waits for its response, or returns nil for a
+// notification once the batch is sent. If ctx is done first, Call returns its error, and the
+// request is left out of the batch if it has not been sent yet.
+func (b *Batcher) Call(ctx context.Context, req *Request) (*Response, error) {
+	if req == nil {
+		return nil, errors.New("request cannot be nil")
+	}
+	// Invalid requests would fail the whole batch
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+	size, err := batchedRequestSize(req)
+	if err != nil {
+		return nil, err
+	}
+
+	call := &batchedCall{ctx: ctx, req: req, done: make(chan struct{})}
+	for _, batch := range b.enqueue(call, size) {
+		go b.send(batch)
+	}
+
+	select {
+	case <-call.done:
+		return call.resp, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// CallBatch sends the requests as a batch of their own, bypassing the batching of calls.
+func (b *Batcher) CallBatch(ctx context.Context, reqs []*Request) ([]*Response, error) {
+	return b.upstream.CallBatch(ctx, reqs)
+}
+
+// Flush sends the current batch without waiting for its window to end, and returns once its
+// calls are answered.
+func (b *Batcher) Flush() {
+	b.mutex.Lock()
+	batch := b.take()
+	b.mutex.Unlock()
+
+	if batch != nil {
+		b.send(batch)
+	}
+}
+
+// enqueue adds call to the current batch and returns the batches that are full and must be sent.
+func (b *Batcher) enqueue(call *batchedCall, size int) []*pendingBatch {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var full []*pendingBatch
+	if b.pending != nil && b.pending.size+size > b.maxBytes {
+		full = append(full, b.take())
+	}
+
+	if b.pending == nil {
+		batch := &pendingBatch{}
+		batch.timer = time.AfterFunc(b.window, func() { b.flushExpired(batch) })
+		b.pending = batch
+	}
+	b.pending.calls = append(b.pending.calls, call)
+	b.pending.size += size
+
+	if len(b.pending.calls) >= b.maxItems || b.pending.size >= b.maxBytes {
+		full = append(full, b.take())
+	}
+	return full
+}
+
+// take removes and returns the current batch, or nil if there is none. The mutex must be held.
+func (b *Batcher) take() *pendingBatch {
+	batch := b.pending
+	if batch != nil {
+		batch.timer.Stop()
+		b.pending = nil
+	}
+	return batch
+}
+
+// flushExpired sends batch at the end of its window, unless it has been sent already.
+func (b *Batcher) flushExpired(batch *pendingBatch) {
+	b.mutex.Lock()
+	if b.pending != batch {
+		b.mutex.Unlock()
+		return
+	}
+	b.take()
+	b.mutex.Unlock()
+
+	b.send(batch)
+}
+
+// send sends the calls of batch whose callers are still waiting, and delivers the responses.
+func (b *Batcher) send(batch *pendingBatch) {
+	calls := make([]*batchedCall, 0, len(batch.calls))
+	for _, call := range batch.calls {
+		if call.ctx.Err() == nil {
+			calls = append(calls, call)
+		}
+	}
+	if len(calls) == 0 {
+		return
+	}
+
+	reqs, ids, err := b.remap(calls)
+	if err != nil {
+		for _, call := range calls {
+			call.finish(nil, err)
+		}
+		return
+	}
+
+	ctx, release := batchContext(calls)
+	defer release()
+	resps, err := b.upstream.CallBatch(ctx, reqs)
+	if err != nil {
+		for _, call := range calls {
+			call.finish(nil, err)
+		}
+		return
+	}
+
+	byID := make(map[ID]*Response, len(resps))
+	for _, resp := range resps {
+		if resp != nil {
+			byID[resp.ID()] = resp
+		}
+	}
+	for i, call := range calls {
+		call.deliver(byID[ids[i]])
+	}
+}
+
+// remap returns the requests of calls with unique IDs, and the IDs they are sent with.
+// Notifications are sent as they are.
+func (b *Batcher) remap(calls []*batchedCall) ([]*Request, []ID, error) {
+	ids, err := uniqueBatchIDs(b.ids, len(calls))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate batch ids: %w", err)
+	}
+
+	reqs := make([]*Request, len(calls))
+	for i, call := range calls {
+		if call.req.IsNotification() {
+			reqs[i] = call.req
+			continue
+		}
+		if reqs[i], err = call.req.WithID(ids[i]); err != nil {
+			return nil, nil, fmt.Errorf("failed to set batch id: %w", err)
+		}
+	}
+	return reqs, ids, nil
+}
+
+// deliver finishes the call with its response from the batch, which is nil if there is none.
+func (c *batchedCall) deliver(resp *Response) {
+	switch {
+	case c.req.IsNotification():
+		c.finish(nil, nil)
+	case resp == nil:
+		c.finish(nil, errors.New("batch response has no response to the request"))
+	default:
+		out, err := resp.WithID(c.req.ID)
+		if err != nil {
+			err = fmt.Errorf("failed to set id of batched response: %w", err)
+		}
+		c.finish(out, err)
+	}
+}
+
+// finish sets the outcome of the call and releases its caller.
+func (c *batchedCall) finish(resp *Response, err error) {
+	c.resp = resp
+	c.err = err
+	close(c.done)
+}
+
+// batchContext returns the context of the upstream call of a batch, which carries the values of
+// the first call and is canceled once every caller is gone, and a function releasing it.
+func batchContext(calls []*batchedCall) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(calls[0].ctx))
+
+	var remaining atomic.Int64
+	remaining.Store(int64(len(calls)))
+	stops := make([]func() bool, len(calls))
+	for i, call := range calls {
+		stops[i] = context.AfterFunc(call.ctx, func() {
+			if remaining.Add(-1) == 0 {
+				cancel()
+			}
+		})
+	}
+
+	return ctx, func() {
+		for _, stop := range stops {
+			stop()
+		}
+		cancel()
+	}
+}
+
+// batchedRequestSize returns the approximate encoded size of req within a batch.
+func batchedRequestSize(req *Request) (int, error) {
+	params, err := req.getParamsBytes()
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode params: %w", err)
+	}
+	// The ID is replaced, so its size is unknown and left out
+	return requestStructureOverhead + len(req.Method) + len(params) + 1, nil
+}
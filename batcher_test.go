@@ -0,0 +1,190 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// callConcurrently calls batcher with the requests concurrently, and returns the outcomes in the
+// order of the requests.
+func callConcurrently(batcher *Batcher, reqs ...*Request) ([]*Response, []error) {
+	resps := make([]*Response, len(reqs))
+	errs := make([]error, len(reqs))
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resps[i], errs[i] = batcher.Call(context.Background(), req)
+		}()
+	}
+	wg.Wait()
+	return resps, errs
+}
+
+func TestNewBatcher(t *testing.T) {
+	_, err := NewBatcher(BatcherConfig{})
+	assert.Error(t, err)
+
+	batcher, err := NewBatcher(BatcherConfig{Upstream: &fakeUpstream{}})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultBatchWindow, batcher.window)
+	assert.Equal(t, DefaultBatchMaxItems, batcher.maxItems)
+	assert.Equal(t, DefaultBatchMaxBytes, batcher.maxBytes)
+}
+
+func TestBatcher_Call(t *testing.T) {
+	t.Run("Concurrent calls share a batch", func(t *testing.T) {
+		upstream := &fakeUpstream{echoMethod: true}
+		batcher, err := NewBatcher(BatcherConfig{Upstream: upstream, Window: time.Minute, MaxItems: 3})
+		require.NoError(t, err)
+
+		// Equal IDs of different callers must not be confused
+		resps, errs := callConcurrently(batcher,
+			NewRequestWithID("a", nil, 1),
+			NewRequestWithID("b", nil, 1),
+			NewRequestWithID("c", nil, "x"),
+		)
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+		assert.Equal(t, []string{"a", "b", "c"}, resultNames(t, resps))
+		assert.Equal(t, "1", resps[0].IDString())
+		assert.Equal(t, "1", resps[1].IDString())
+		assert.Equal(t, "x", resps[2].IDString())
+		assert.Equal(t, []int{3}, upstream.batchSizes())
+	})
+
+	t.Run("Window ends a batch", func(t *testing.T) {
+		upstream := &fakeUpstream{echoMethod: true}
+		batcher, err := NewBatcher(BatcherConfig{Upstream: upstream, Window: 10 * time.Millisecond})
+		require.NoError(t, err)
+
+		resps, errs := callConcurrently(batcher, NewRequest("a", nil), NewRequest("b", nil))
+		require.NoError(t, errors.Join(errs...))
+		assert.Equal(t, []string{"a", "b"}, resultNames(t, resps))
+		assert.Equal(t, []int{2}, upstream.batchSizes())
+	})
+
+	t.Run("MaxBytes splits batches", func(t *testing.T) {
+		upstream := &fakeUpstream{echoMethod: true}
+		newReq := func() *Request { return NewRequest("m", []any{strings.Repeat("x", 100)}) }
+		size, err := batchedRequestSize(newReq())
+		require.NoError(t, err)
+
+		batcher, err := NewBatcher(BatcherConfig{
+			Upstream: upstream,
+			Window:   10 * time.Millisecond,
+			MaxBytes: 2 * size,
+		})
+		require.NoError(t, err)
+
+		_, errs := callConcurrently(batcher, newReq(), newReq(), newReq())
+		require.NoError(t, errors.Join(errs...))
+		assert.Equal(t, []int{2, 1}, upstream.batchSizes())
+	})
+
+	t.Run("Missing response", func(t *testing.T) {
+		upstream := &fakeUpstream{echoMethod: true}
+		upstream.respond = func(reqs []*Request) ([]*Response, error) {
+			resp, err := NewResponse(reqs[0].ID, reqs[0].Method)
+			return []*Response{resp}, err
+		}
+		batcher, err := NewBatcher(BatcherConfig{Upstream: upstream, Window: time.Minute, MaxItems: 2})
+		require.NoError(t, err)
+
+		resps, errs := callConcurrently(batcher, NewRequest("a", nil), NewRequest("b", nil))
+		answered := 0
+		for i := range resps {
+			if errs[i] == nil {
+				answered++
+				continue
+			}
+			assert.ErrorContains(t, errs[i], "no response")
+		}
+		assert.Equal(t, 1, answered)
+	})
+
+	t.Run("Failed batch fails every call", func(t *testing.T) {
+		upstream := &fakeUpstream{echoMethod: true}
+		upstream.respond = func([]*Request) ([]*Response, error) {
+			return nil, errors.New("connection reset")
+		}
+		batcher, err := NewBatcher(BatcherConfig{Upstream: upstream, Window: time.Minute, MaxItems: 2})
+		require.NoError(t, err)
+
+		_, errs := callConcurrently(batcher, NewRequest("a", nil), NewRequest("b", nil))
+		for _, err := range errs {
+			assert.EqualError(t, err, "connection reset")
+		}
+	})
+
+	t.Run("Notifications", func(t *testing.T) {
+		upstream := &fakeUpstream{echoMethod: true}
+		batcher, err := NewBatcher(BatcherConfig{Upstream: upstream, Window: time.Minute, MaxItems: 2})
+		require.NoError(t, err)
+
+		resps, errs := callConcurrently(batcher, NewNotification("n", nil), NewRequest("a", nil))
+		require.NoError(t, errors.Join(errs...))
+		assert.Nil(t, resps[0])
+		assert.Equal(t, []string{"a"}, resultNames(t, resps[1:]))
+	})
+
+	t.Run("Canceled calls are left out", func(t *testing.T) {
+		upstream := &fakeUpstream{echoMethod: true}
+		batcher, err := NewBatcher(BatcherConfig{Upstream: upstream, Window: time.Minute})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = batcher.Call(ctx, NewRequest("a", nil))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		batcher.Flush()
+		assert.Empty(t, upstream.batchSizes())
+	})
+
+	t.Run("Invalid requests are rejected", func(t *testing.T) {
+		batcher, err := NewBatcher(BatcherConfig{Upstream: &fakeUpstream{}})
+		require.NoError(t, err)
+
+		_, err = batcher.Call(context.Background(), nil)
+		assert.Error(t, err)
+		_, err = batcher.Call(context.Background(), NewRequest("", nil))
+		assert.Error(t, err)
+	})
+}
+
+func TestBatcher_HTTPUpstream(t *testing.T) {
+	server := newEchoServer(t)
+	upstream, err := NewHTTPUpstream(HTTPUpstreamConfig{URL: server.URL})
+	require.NoError(t, err)
+	batcher, err := NewBatcher(BatcherConfig{
+		Upstream:    upstream,
+		Window:      time.Minute,
+		MaxItems:    2,
+		IDGenerator: NewPrefixedIDGenerator("batch-"),
+	})
+	require.NoError(t, err)
+
+	resps, errs := callConcurrently(batcher,
+		NewRequestWithID("eth_chainId", nil, 7),
+		NewRequestWithID("eth_blockNumber", nil, 7),
+	)
+	require.NoError(t, errors.Join(errs...))
+
+	for i, method := range []string{"eth_chainId", "eth_blockNumber"} {
+		var result map[string]string
+		require.NoError(t, resps[i].UnmarshalResult(&result))
+		assert.Equal(t, method, result["method"])
+		assert.True(t, strings.HasPrefix(result["upstreamId"], "batch-"))
+		assert.Equal(t, "7", resps[i].IDString())
+	}
+}
@@ -208,16 +208,11 @@ func BenchmarkDecodeBatchResponse(b *testing.B) {
 // TODO: Add comparison benchmarks for alternative JSON parsers
 // TODO: Add benchmarks for different param types (nil, array, object)
 func BenchmarkRequestMarshal(b *testing.B) {
-	req := &Request{
-		JSONRPC: "2.0",
-		ID:      int64(42),
-		Method:  "updateUser",
-		Params: map[string]any{
-			"userId": 12345,
-			"name":   "Alice Johnson",
-			"email":  "alice@example.com",
-		},
-	}
+	req := NewRequestWithID("updateUser", map[string]any{
+		"userId": 12345,
+		"name":   "Alice Johnson",
+		"email":  "alice@example.com",
+	}, int64(42))
 
 	b.ReportAllocs()
 	for b.Loop() {
@@ -234,7 +229,7 @@ func BenchmarkRequestMarshal(b *testing.B) {
 func BenchmarkResponseMarshal(b *testing.B) {
 	resp := &Response{
 		jsonrpc: "2.0",
-		id:      int64(42),
+		id:      Int64ID(42),
 		result:  []byte(`{"userId":12345,"name":"Alice Johnson","status":"active"}`),
 	}
 
@@ -418,21 +413,16 @@ func BenchmarkProfiles_RequestMarshal(b *testing.B) {
 		ProfileAggressive,
 	}
 
-	req := &Request{
-		JSONRPC: "2.0",
-		ID:      int64(42),
-		Method:  "updateUser",
-		Params: map[string]any{
-			"userId": 12345,
-			"name":   "Alice Johnson",
-			"email":  "alice@example.com",
-			"preferences": map[string]any{
-				"theme":         "dark",
-				"language":      "en",
-				"notifications": true,
-			},
+	req := NewRequestWithID("updateUser", map[string]any{
+		"userId": 12345,
+		"name":   "Alice Johnson",
+		"email":  "alice@example.com",
+		"preferences": map[string]any{
+			"theme":         "dark",
+			"language":      "en",
+			"notifications": true,
 		},
-	}
+	}, int64(42))
 
 	for _, profile := range profiles {
 		b.Run(profile.String(), func(b *testing.B) {
@@ -0,0 +1,255 @@
+package jsonrpc
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// cacheSweepMinLen is the minimum number of entries at which a ResponseCache sweeps expired
+// entries.
+const cacheSweepMinLen = 64
+
+// CacheConfig configures a ResponseCache.
+type CacheConfig struct {
+	// MethodTTLs holds the time to live of cached responses per method. Methods without an entry
+	// use DefaultTTL.
+	MethodTTLs map[string]time.Duration
+
+	// DefaultTTL is the time to live of cached responses for methods not in MethodTTLs. A TTL of
+	// zero or less disables caching, so with the zero value only the methods in MethodTTLs are
+	// cached.
+	DefaultTTL time.Duration
+
+	// MaxSize bounds the total size of the cached responses in bytes, as estimated by
+	// Response.Size. When it is exceeded, the least recently used responses are evicted. Zero
+	// means no limit.
+	MaxSize int
+
+	// CacheErrors enables caching of error responses, which are not cached by default.
+	CacheErrors bool
+}
+
+// ResponseCache caches responses keyed by the canonical form of their request, so that
+// semantically equal requests share an entry regardless of their ID, member order or formatting.
+// See Request.Hash. Hits are returned with the ID of the request they are served for.
+//
+// Expired entries are removed when they are looked up, and swept whenever the number of entries
+// has doubled since the last sweep, so that entries that are never looked up again do not
+// accumulate.
+//
+// A ResponseCache is safe for concurrent use.
+//
+// Example usage:
+//
+//	cache := jsonrpc.NewResponseCache(jsonrpc.CacheConfig{
+//		MethodTTLs: map[string]time.Duration{"eth_getBlockByHash": time.Hour},
+//		MaxSize:    64 << 20,
+//	})
+//	handler = cache.Wrap(handler)
+type ResponseCache struct {
+	config CacheConfig
+
+	mutex   sync.Mutex
+	entries map[[sha256.Size]byte]*list.Element
+	lru     *list.List // Front is most recently used
+	size    int
+
+	// sweepLen is the number of entries at which Set sweeps expired entries
+	sweepLen int
+
+	now func() time.Time
+}
+
+// cacheEntry is a cached response with its key, expiry and accounted size.
+type cacheEntry struct {
+	key     [sha256.Size]byte
+	resp    *Response
+	expires time.Time
+	size    int
+}
+
+// NewResponseCache creates a ResponseCache with the given configuration.
+func NewResponseCache(config CacheConfig) *ResponseCache {
+	return &ResponseCache{
+		config:   config,
+		entries:  make(map[[sha256.Size]byte]*list.Element),
+		lru:      list.New(),
+		sweepLen: cacheSweepMinLen,
+		now:      time.Now,
+	}
+}
+
+// Get returns the cached response for req with its ID set to the ID of req, and false if there
+// is no unexpired entry. Notifications are never served from the cache.
+func (c *ResponseCache) Get(req *Request) (*Response, bool) {
+	if req == nil || req.IsNotification() {
+		return nil, false
+	}
+	key, err := req.Hash()
+	if err != nil {
+		return nil, false
+	}
+
+	c.mutex.Lock()
+	elem, ok := c.entries[key]
+	if !ok {
+		c.mutex.Unlock()
+		return nil, false
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.removeElement(elem)
+		c.mutex.Unlock()
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	cached := entry.resp
+	c.mutex.Unlock()
+
+	resp, err := cached.WithID(req.ID)
+	if err != nil {
+		return nil, false
+	}
+	return resp, true
+}
+
+// Set caches resp as the response to req and reports whether it was stored. Responses are not
+// stored if their method has no positive TTL, if they are error responses and CacheErrors is not
+// set, or if they are larger than MaxSize. The cache stores a copy of resp.
+func (c *ResponseCache) Set(req *Request, resp *Response) bool {
+	if req == nil || resp == nil || req.IsNotification() {
+		return false
+	}
+	if resp.Err() != nil && !c.config.CacheErrors {
+		return false
+	}
+	ttl := c.ttl(req.Method)
+	if ttl <= 0 {
+		return false
+	}
+	size := resp.Size()
+	if c.config.MaxSize > 0 && size > c.config.MaxSize {
+		return false
+	}
+	key, err := req.Hash()
+	if err != nil {
+		return false
+	}
+	clone, err := resp.Clone()
+	if err != nil {
+		return false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.removeElement(elem)
+	}
+	now := c.now()
+	entry := &cacheEntry{key: key, resp: clone, expires: now.Add(ttl), size: size}
+	c.entries[key] = c.lru.PushFront(entry)
+	c.size += size
+
+	if c.lru.Len() >= c.sweepLen {
+		c.removeExpired(now)
+		c.sweepLen = max(2*c.lru.Len(), cacheSweepMinLen)
+	}
+
+	for c.config.MaxSize > 0 && c.size > c.config.MaxSize {
+		c.removeElement(c.lru.Back())
+	}
+
+	return true
+}
+
+// Delete removes the cached response for req, if any.
+func (c *ResponseCache) Delete(req *Request) {
+	if req == nil {
+		return
+	}
+	key, err := req.Hash()
+	if err != nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// Clear removes all cached responses.
+func (c *ResponseCache) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	clear(c.entries)
+	c.lru.Init()
+	c.size = 0
+	c.sweepLen = cacheSweepMinLen
+}
+
+// Len returns the number of cached responses, including expired ones not yet evicted.
+func (c *ResponseCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.lru.Len()
+}
+
+// Size returns the total size of the cached responses in bytes, as estimated by Response.Size.
+func (c *ResponseCache) Size() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.size
+}
+
+// Wrap returns a HandlerFunc that serves requests from the cache, and calls next and caches its
+// response on a miss. Responses are not cached if next returns an error. Since a HandlerFunc
+// serves both sides of a connection, Wrap can be used on a serving handler and on a client alike.
+func (c *ResponseCache) Wrap(next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, req *Request) (*Response, error) {
+		if resp, ok := c.Get(req); ok {
+			return resp, nil
+		}
+
+		resp, err := next(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+		c.Set(req, resp)
+
+		return resp, nil
+	}
+}
+
+// ttl returns the time to live of responses to method.
+func (c *ResponseCache) ttl(method string) time.Duration {
+	if ttl, ok := c.config.MethodTTLs[method]; ok {
+		return ttl
+	}
+	return c.config.DefaultTTL
+}
+
+// removeElement removes elem from the cache. The caller must hold the mutex.
+func (c *ResponseCache) removeElement(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*cacheEntry)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+}
+
+// removeExpired removes the entries that have expired at now. The caller must hold the mutex.
+func (c *ResponseCache) removeExpired(now time.Time) {
+	for elem := c.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		if !now.Before(elem.Value.(*cacheEntry).expires) {
+			c.removeElement(elem)
+		}
+		elem = prev
+	}
+}
@@ -0,0 +1,197 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingHandler returns a HandlerFunc answering every request with result, and a pointer to the
+// number of calls made to it.
+func countingHandler(result any) (HandlerFunc, *int) {
+	calls := 0
+	return func(_ context.Context, req *Request) (*Response, error) {
+		calls++
+		return NewResponse(req.ID, result)
+	}, &calls
+}
+
+func TestResponseCache_GetSet(t *testing.T) {
+	cache := NewResponseCache(CacheConfig{
+		MethodTTLs: map[string]time.Duration{"cached": time.Minute},
+	})
+
+	req := NewRequestWithID("cached", []any{"a", 1}, int64(1))
+	resp, err := NewResponse(req.ID, map[string]any{"value": 1})
+	require.NoError(t, err)
+	require.True(t, cache.Set(req, resp))
+	assert.Equal(t, 1, cache.Len())
+	assert.Equal(t, resp.Size(), cache.Size())
+
+	t.Run("Hit with the caller's ID", func(t *testing.T) {
+		other, err := DecodeRequest([]byte(
+			`{"jsonrpc":"2.0","id":"other","method":"cached","params":["a",1.0]}`))
+		require.NoError(t, err)
+
+		hit, ok := cache.Get(other)
+		require.True(t, ok)
+		assert.Equal(t, "other", hit.IDString())
+		assert.JSONEq(t, `{"value":1}`, string(hit.RawResult()))
+		assert.Equal(t, "1", resp.IDString())
+	})
+
+	t.Run("Miss", func(t *testing.T) {
+		_, ok := cache.Get(NewRequest("cached", []any{"b", 1}))
+		assert.False(t, ok)
+		_, ok = cache.Get(NewNotification("cached", []any{"a", 1}))
+		assert.False(t, ok)
+	})
+
+	t.Run("Methods without TTL are not cached", func(t *testing.T) {
+		other := NewRequest("uncached", nil)
+		resp, err := NewResponse(other.ID, true)
+		require.NoError(t, err)
+		assert.False(t, cache.Set(other, resp))
+	})
+
+	t.Run("Delete and Clear", func(t *testing.T) {
+		cache.Delete(req)
+		_, ok := cache.Get(req)
+		assert.False(t, ok)
+
+		require.True(t, cache.Set(req, resp))
+		cache.Clear()
+		assert.Equal(t, 0, cache.Len())
+		assert.Equal(t, 0, cache.Size())
+	})
+}
+
+func TestResponseCache_TTL(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := NewResponseCache(CacheConfig{
+		DefaultTTL: time.Second,
+		MethodTTLs: map[string]time.Duration{"long": time.Hour, "never": 0},
+	})
+	cache.now = func() time.Time { return now }
+
+	short := NewRequest("short", nil)
+	long := NewRequest("long", nil)
+	for _, req := range []*Request{short, long} {
+		resp, err := NewResponse(req.ID, "x")
+		require.NoError(t, err)
+		require.True(t, cache.Set(req, resp))
+	}
+	never := NewRequest("never", nil)
+	resp, err := NewResponse(never.ID, "x")
+	require.NoError(t, err)
+	assert.False(t, cache.Set(never, resp))
+
+	now = now.Add(time.Second)
+	_, ok := cache.Get(short)
+	assert.False(t, ok)
+	_, ok = cache.Get(long)
+	assert.True(t, ok)
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestResponseCache_Sweep(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := NewResponseCache(CacheConfig{DefaultTTL: time.Second})
+	cache.now = func() time.Time { return now }
+
+	set := func(n int) int {
+		req := NewRequest("m", []any{n})
+		resp, err := NewResponse(req.ID, "x")
+		require.NoError(t, err)
+		require.True(t, cache.Set(req, resp))
+		return resp.Size()
+	}
+
+	// Entries that are never looked up again must not accumulate without MaxSize
+	for n := range cacheSweepMinLen {
+		set(n)
+	}
+	now = now.Add(time.Second)
+	size := 0
+	for n := range cacheSweepMinLen {
+		size += set(cacheSweepMinLen + n)
+	}
+	assert.Equal(t, cacheSweepMinLen, cache.Len(), "expired entries should be swept")
+	assert.Equal(t, size, cache.Size())
+}
+
+func TestResponseCache_Eviction(t *testing.T) {
+	newPair := func(n int) (*Request, *Response) {
+		req := NewRequestWithID("m", []any{n}, int64(n))
+		resp, err := NewResponse(req.ID, "0123456789")
+		require.NoError(t, err)
+		return req, resp
+	}
+
+	req1, resp1 := newPair(1)
+	cache := NewResponseCache(CacheConfig{DefaultTTL: time.Minute, MaxSize: 2 * resp1.Size()})
+	req2, resp2 := newPair(2)
+	req3, resp3 := newPair(3)
+
+	require.True(t, cache.Set(req1, resp1))
+	require.True(t, cache.Set(req2, resp2))
+	_, ok := cache.Get(req1) // req1 becomes the most recently used
+	require.True(t, ok)
+	require.True(t, cache.Set(req3, resp3))
+
+	_, ok = cache.Get(req2)
+	assert.False(t, ok, "least recently used entry should be evicted")
+	_, ok = cache.Get(req1)
+	assert.True(t, ok)
+	_, ok = cache.Get(req3)
+	assert.True(t, ok)
+	assert.LessOrEqual(t, cache.Size(), 2*resp1.Size())
+
+	t.Run("Responses larger than MaxSize are not cached", func(t *testing.T) {
+		req := NewRequest("m", []any{4})
+		resp, err := NewResponse(req.ID, make([]int, 100))
+		require.NoError(t, err)
+		assert.False(t, cache.Set(req, resp))
+	})
+}
+
+func TestResponseCache_Errors(t *testing.T) {
+	req := NewRequest("m", nil)
+	errResp := NewErrorResponse(req.ID, &Error{Code: ServerSideException, Message: "boom"})
+
+	cache := NewResponseCache(CacheConfig{DefaultTTL: time.Minute})
+	assert.False(t, cache.Set(req, errResp))
+
+	cache = NewResponseCache(CacheConfig{DefaultTTL: time.Minute, CacheErrors: true})
+	require.True(t, cache.Set(req, errResp))
+	hit, ok := cache.Get(NewRequest("m", nil))
+	require.True(t, ok)
+	assert.Equal(t, "boom", hit.Err().Message)
+}
+
+func TestResponseCache_Wrap(t *testing.T) {
+	cache := NewResponseCache(CacheConfig{DefaultTTL: time.Minute})
+	next, calls := countingHandler("result")
+	h := cache.Wrap(next)
+
+	for i := range 3 {
+		req := NewRequestWithID("m", []any{"a"}, int64(i))
+		resp, err := h(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, req.ID, resp.ID())
+	}
+	assert.Equal(t, 1, *calls)
+
+	t.Run("Errors are passed through and not cached", func(t *testing.T) {
+		failing := cache.Wrap(func(context.Context, *Request) (*Response, error) {
+			return nil, errors.New("unavailable")
+		})
+		_, err := failing(context.Background(), NewRequest("other", nil))
+		assert.EqualError(t, err, "unavailable")
+		assert.Equal(t, 1, cache.Len())
+	})
+}
@@ -0,0 +1,203 @@
+package jsonrpc
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic/ast"
+)
+
+// maxCanonicalExponent bounds the exponent of numbers that are normalized exactly. Numbers with
+// larger exponents are kept in their original form to avoid expanding them into huge decimals.
+const maxCanonicalExponent = 1000
+
+// CanonicalBytes returns a canonical encoding of the request that ignores the id, member order and
+// insignificant whitespace, suitable as a cache or deduplication key. The encoding is a JSON
+// object holding the method and, if present, the params, where:
+//   - object members are sorted by key
+//   - strings are re-encoded with a single escaping convention
+//   - numbers are normalized, so 1, 1.0 and 1e0 encode identically
+//
+// Two requests that differ only in id, formatting or member order have equal canonical bytes.
+func (r *Request) CanonicalBytes() ([]byte, error) {
+	params, err := r.getParamsBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	dst := make([]byte, 0, requestStructureOverhead+len(r.Method)+len(params))
+	dst = append(dst, `{"method":`...)
+	dst = appendJSONString(dst, r.Method)
+	if params != nil {
+		dst = append(dst, `,"params":`...)
+		if dst, err = appendCanonicalJSON(dst, params); err != nil {
+			return nil, fmt.Errorf("failed to canonicalize params: %w", err)
+		}
+	}
+
+	return append(dst, '}'), nil
+}
+
+// Hash returns the SHA-256 digest of the canonical encoding of the request. See CanonicalBytes.
+func (r *Request) Hash() ([sha256.Size]byte, error) {
+	canonical, err := r.CanonicalBytes()
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(canonical), nil
+}
+
+// CanonicalResult returns the canonical encoding of the result, using the same rules as
+// Request.CanonicalBytes, so that semantically equal results compare equal byte for byte.
+func (r *Response) CanonicalResult() ([]byte, error) {
+	if len(r.result) == 0 {
+		return nil, errors.New("response has no result field")
+	}
+	return appendCanonicalJSON(make([]byte, 0, len(r.result)), r.result)
+}
+
+// ResultHash returns the SHA-256 digest of the canonical encoding of the result.
+func (r *Response) ResultHash() ([sha256.Size]byte, error) {
+	canonical, err := r.CanonicalResult()
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(canonical), nil
+}
+
+// appendCanonicalJSON appends the canonical encoding of the JSON document data to dst.
+func appendCanonicalJSON(dst, data []byte) ([]byte, error) {
+	root := ast.NewRaw(string(data))
+	if err := root.Check(); err != nil {
+		return nil, err
+	}
+	return appendCanonicalNode(dst, &root)
+}
+
+// appendCanonicalNode appends the canonical encoding of node to dst.
+func appendCanonicalNode(dst []byte, node *ast.Node) ([]byte, error) {
+	switch node.TypeSafe() {
+	case ast.V_NULL:
+		return append(dst, "null"...), nil
+	case ast.V_TRUE:
+		return append(dst, "true"...), nil
+	case ast.V_FALSE:
+		return append(dst, "false"...), nil
+	case ast.V_STRING:
+		str, err := node.String()
+		if err != nil {
+			return nil, err
+		}
+		return appendJSONString(dst, str), nil
+	case ast.V_NUMBER:
+		raw, err := node.Raw()
+		if err != nil {
+			return nil, err
+		}
+		return append(dst, canonicalNumber(raw)...), nil
+	case ast.V_ARRAY:
+		return appendCanonicalArray(dst, node)
+	case ast.V_OBJECT:
+		return appendCanonicalObject(dst, node)
+	default:
+		return nil, fmt.Errorf("unsupported JSON value type %d", node.TypeSafe())
+	}
+}
+
+// appendCanonicalArray appends the canonical encoding of an array node to dst.
+func appendCanonicalArray(dst []byte, node *ast.Node) ([]byte, error) {
+	dst = append(dst, '[')
+
+	var err error
+	first := true
+	iterErr := node.ForEach(func(_ ast.Sequence, child *ast.Node) bool {
+		if !first {
+			dst = append(dst, ',')
+		}
+		first = false
+		dst, err = appendCanonicalNode(dst, child)
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if iterErr != nil {
+		return nil, iterErr
+	}
+
+	return append(dst, ']'), nil
+}
+
+// appendCanonicalObject appends the canonical encoding of an object node to dst, with members
+// sorted by key. If a key occurs more than once, the last occurrence wins.
+func appendCanonicalObject(dst []byte, node *ast.Node) ([]byte, error) {
+	type member struct {
+		key   string
+		value *ast.Node
+	}
+
+	var members []member
+	err := node.ForEach(func(seq ast.Sequence, child *ast.Node) bool {
+		members = append(members, member{key: *seq.Key, value: child})
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Stable sort keeps duplicates in document order, so that the last one can be selected
+	slices.SortStableFunc(members, func(a, b member) int {
+		return strings.Compare(a.key, b.key)
+	})
+
+	dst = append(dst, '{')
+	first := true
+	for i, m := range members {
+		if i+1 < len(members) && members[i+1].key == m.key {
+			continue
+		}
+		if !first {
+			dst = append(dst, ',')
+		}
+		first = false
+
+		dst = appendJSONString(dst, m.key)
+		dst = append(dst, ':')
+		if dst, err = appendCanonicalNode(dst, m.value); err != nil {
+			return nil, err
+		}
+	}
+
+	return append(dst, '}'), nil
+}
+
+// canonicalNumber returns the shortest exact decimal form of a JSON number, e.g. "1.50e1" becomes
+// "15" and "-0.0" becomes "0". Numbers with very large exponents are returned as-is.
+func canonicalNumber(raw string) string {
+	isInteger := !strings.ContainsAny(raw, ".eE")
+	if isInteger && !strings.HasPrefix(raw, "-0") {
+		return raw
+	}
+
+	if i := strings.IndexAny(raw, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(raw[i+1:])
+		if err != nil || exp > maxCanonicalExponent || exp < -maxCanonicalExponent {
+			return raw
+		}
+	}
+
+	rat, ok := new(big.Rat).SetString(raw)
+	if !ok {
+		return raw
+	}
+	prec, exact := rat.FloatPrec()
+	if !exact {
+		return raw
+	}
+	return rat.FloatString(prec)
+}
@@ -0,0 +1,123 @@
+package jsonrpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequest_CanonicalBytes(t *testing.T) {
+	t.Run("Ignores id, member order and whitespace", func(t *testing.T) {
+		a, err := DecodeRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_call",
+			"params":[{"to":"0x1","data":"0x"},"latest"]}`))
+		require.NoError(t, err)
+		b, err := DecodeRequest([]byte(`{"method":"eth_call","params":[ {"data":"0x",
+			"to":"0x1"} , "latest" ],"id":"other","jsonrpc":"2.0"}`))
+		require.NoError(t, err)
+
+		canonicalA, err := a.CanonicalBytes()
+		require.NoError(t, err)
+		canonicalB, err := b.CanonicalBytes()
+		require.NoError(t, err)
+		assert.Equal(t, `{"method":"eth_call","params":[{"data":"0x","to":"0x1"},"latest"]}`,
+			string(canonicalA))
+		assert.Equal(t, canonicalA, canonicalB)
+
+		hashA, err := a.Hash()
+		require.NoError(t, err)
+		hashB, err := b.Hash()
+		require.NoError(t, err)
+		assert.Equal(t, hashA, hashB)
+	})
+
+	t.Run("Decoded and constructed requests match", func(t *testing.T) {
+		decoded, err := DecodeRequest([]byte(
+			`{"jsonrpc":"2.0","id":1,"method":"m","params":{"b":[1.0,2e0],"a":"A"}}`))
+		require.NoError(t, err)
+		built := NewRequest("m", map[string]any{"a": "A", "b": []int{1, 2}})
+
+		hashDecoded, err := decoded.Hash()
+		require.NoError(t, err)
+		hashBuilt, err := built.Hash()
+		require.NoError(t, err)
+		assert.Equal(t, hashDecoded, hashBuilt)
+	})
+
+	t.Run("Different requests differ", func(t *testing.T) {
+		base := NewRequest("m", []any{1})
+		for _, other := range []*Request{
+			NewRequest("n", []any{1}),
+			NewRequest("m", []any{2}),
+			NewRequest("m", []any{"1"}),
+			NewRequest("m", []any{}),
+			NewRequest("m", nil),
+		} {
+			h1, err := base.Hash()
+			require.NoError(t, err)
+			h2, err := other.Hash()
+			require.NoError(t, err)
+			assert.NotEqual(t, h1, h2)
+		}
+	})
+
+	t.Run("Without params", func(t *testing.T) {
+		canonical, err := NewNotification("ping", nil).CanonicalBytes()
+		require.NoError(t, err)
+		assert.Equal(t, `{"method":"ping"}`, string(canonical))
+	})
+
+	t.Run("Duplicate keys keep the last value", func(t *testing.T) {
+		req := NewRequestWithRawParams("m", []byte(`{"a":1,"b":2,"a":3}`))
+
+		canonical, err := req.CanonicalBytes()
+		require.NoError(t, err)
+		assert.Equal(t, `{"method":"m","params":{"a":3,"b":2}}`, string(canonical))
+	})
+
+	t.Run("Invalid params", func(t *testing.T) {
+		_, err := NewRequestWithRawParams("m", []byte(`{"a":`)).CanonicalBytes()
+		assert.Error(t, err)
+		_, err = NewRequest("m", []any{make(chan int)}).Hash()
+		assert.Error(t, err)
+	})
+}
+
+func TestResponse_CanonicalResult(t *testing.T) {
+	a := mustDecodeResponse(t, `{"jsonrpc":"2.0","id":1,"result":{"y":[1.50,true,null],"x":"s"}}`)
+	b := mustDecodeResponse(t, `{"jsonrpc":"2.0","id":2,"result":{"x":"s","y":[15e-1,true,null]}}`)
+
+	canonical, err := a.CanonicalResult()
+	require.NoError(t, err)
+	assert.Equal(t, `{"x":"s","y":[1.5,true,null]}`, string(canonical))
+
+	hashA, err := a.ResultHash()
+	require.NoError(t, err)
+	hashB, err := b.ResultHash()
+	require.NoError(t, err)
+	assert.Equal(t, hashA, hashB)
+
+	_, err = NewErrorResponse(1, &Error{Code: 1, Message: "m"}).CanonicalResult()
+	assert.Error(t, err)
+}
+
+func TestCanonicalNumber(t *testing.T) {
+	cases := map[string]string{
+		"0":                    "0",
+		"-0":                   "0",
+		"-0.0":                 "0",
+		"42":                   "42",
+		"-7":                   "-7",
+		"1.0":                  "1",
+		"1.50":                 "1.5",
+		"1e3":                  "1000",
+		"1.5E+2":               "150",
+		"25e-1":                "2.5",
+		"18446744073709551616": "18446744073709551616",
+		"0.1":                  "0.1",
+		"1e5000":               "1e5000",
+	}
+	for in, want := range cases {
+		assert.Equal(t, want, canonicalNumber(in), in)
+	}
+}
@@ -0,0 +1,159 @@
+package jsonrpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// Coalescer collapses concurrent requests with equal canonical method and params into a single
+// call, and fans the response out to every caller with the caller's own ID. See Request.Hash.
+//
+// The shared call runs with a context that carries the values of the context of the first
+// caller, but not its cancellation. Each caller stops waiting when its own context is done, and
+// the shared call is canceled once no caller is waiting for it anymore. The request of the first
+// caller is passed to the shared call, so it must not be reused while the call is in flight.
+//
+// Notifications and requests whose params cannot be canonicalized are passed through. If the
+// shared call panics, the panic is recovered and raised again in every caller waiting for it, with
+// a value that is an error describing the original panic value and stack.
+//
+// A Coalescer is safe for concurrent use.
+//
+// Example usage:
+//
+//	coalescer := jsonrpc.NewCoalescer()
+//	client = coalescer.Wrap(client)
+type Coalescer struct {
+	mutex sync.Mutex
+	calls map[[sha256.Size]byte]*coalescedCall
+}
+
+// coalescedCall is a call shared by the callers waiting for it.
+type coalescedCall struct {
+	done   chan struct{}
+	cancel context.CancelFunc
+
+	// Set before done is closed
+	resp  *Response
+	err   error
+	panic *coalescedPanic
+
+	// Guarded by the mutex of the Coalescer
+	waiters int
+}
+
+// NewCoalescer creates a Coalescer.
+func NewCoalescer() *Coalescer {
+	return &Coalescer{
+		calls: make(map[[sha256.Size]byte]*coalescedCall),
+	}
+}
+
+// Wrap returns a HandlerFunc that coalesces concurrent equal requests into a single call to next.
+func (c *Coalescer) Wrap(next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, req *Request) (*Response, error) {
+		if req == nil || req.IsNotification() {
+			return next(ctx, req)
+		}
+		key, err := req.Hash()
+		if err != nil {
+			return next(ctx, req)
+		}
+
+		c.mutex.Lock()
+		call, ok := c.calls[key]
+		if !ok {
+			callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+			call = &coalescedCall{done: make(chan struct{}), cancel: cancel}
+			c.calls[key] = call
+			go c.execute(callCtx, key, call, next, req)
+		}
+		call.waiters++
+		c.mutex.Unlock()
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			c.leave(key, call)
+			return nil, ctx.Err()
+		}
+
+		if call.panic != nil {
+			panic(call.panic)
+		}
+		if call.err != nil {
+			return nil, call.err
+		}
+		if call.resp == nil {
+			return nil, nil
+		}
+		resp, err := call.resp.WithID(req.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to set id of coalesced response: %w", err)
+		}
+		return resp, nil
+	}
+}
+
+// InFlight returns the number of shared calls in flight.
+func (c *Coalescer) InFlight() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.calls)
+}
+
+// execute runs the shared call and publishes its outcome to the waiting callers.
+func (c *Coalescer) execute(
+	ctx context.Context,
+	key [sha256.Size]byte,
+	call *coalescedCall,
+	next HandlerFunc,
+	req *Request,
+) {
+	defer call.cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			call.panic = &coalescedPanic{value: r, stack: debug.Stack()}
+		}
+
+		c.mutex.Lock()
+		if c.calls[key] == call {
+			delete(c.calls, key)
+		}
+		c.mutex.Unlock()
+
+		close(call.done)
+	}()
+
+	call.resp, call.err = next(ctx, req)
+}
+
+// leave unregisters a caller that stopped waiting for call, and cancels the call if it was the
+// last one waiting.
+func (c *Coalescer) leave(key [sha256.Size]byte, call *coalescedCall) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+	call.cancel()
+}
+
+// coalescedPanic is the value a shared call panicked with, raised again in its waiting callers.
+type coalescedPanic struct {
+	value any
+	stack []byte
+}
+
+// Error implements the error interface.
+func (p *coalescedPanic) Error() string {
+	return fmt.Sprintf("coalesced call panicked: %v\n\n%s", p.value, p.stack)
+}
@@ -0,0 +1,194 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingHandler returns a HandlerFunc that blocks until release is closed or its context is
+// done, and counts its calls. Each call is signaled on started.
+func blockingHandler(release <-chan struct{}) (HandlerFunc, *atomic.Int32, chan context.Context) {
+	var calls atomic.Int32
+	started := make(chan context.Context, 16)
+	return func(ctx context.Context, req *Request) (*Response, error) {
+		calls.Add(1)
+		started <- ctx
+		select {
+		case <-release:
+			return NewResponse(req.ID, "latest")
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}, &calls, started
+}
+
+func TestCoalescer(t *testing.T) {
+	t.Run("Equal requests share a call", func(t *testing.T) {
+		release := make(chan struct{})
+		next, calls, started := blockingHandler(release)
+		coalescer := NewCoalescer()
+		h := coalescer.Wrap(next)
+
+		const callers = 50
+		var wg sync.WaitGroup
+		responses := make([]*Response, callers)
+		errs := make([]error, callers)
+		for i := range callers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req := NewRequestWithID("eth_blockNumber", []any{}, int64(i))
+				responses[i], errs[i] = h(context.Background(), req)
+			}()
+		}
+
+		<-started
+		require.Eventually(t, func() bool {
+			coalescer.mutex.Lock()
+			defer coalescer.mutex.Unlock()
+			for _, call := range coalescer.calls {
+				return call.waiters == callers
+			}
+			return false
+		}, time.Second, time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls.Load())
+		assert.Equal(t, 0, coalescer.InFlight())
+		for i := range callers {
+			require.NoError(t, errs[i])
+			assert.Equal(t, strconv.Itoa(i), responses[i].IDString())
+			assert.JSONEq(t, `"latest"`, string(responses[i].RawResult()))
+		}
+	})
+
+	t.Run("Different requests do not share a call", func(t *testing.T) {
+		release := make(chan struct{})
+		close(release)
+		next, calls, _ := blockingHandler(release)
+		h := NewCoalescer().Wrap(next)
+
+		_, err := h(context.Background(), NewRequest("m", []any{1}))
+		require.NoError(t, err)
+		_, err = h(context.Background(), NewRequest("m", []any{2}))
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("Callers cancel independently", func(t *testing.T) {
+		release := make(chan struct{})
+		next, calls, started := blockingHandler(release)
+		coalescer := NewCoalescer()
+		h := coalescer.Wrap(next)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		firstErr := make(chan error, 1)
+		go func() {
+			_, err := h(ctx, NewRequest("m", nil))
+			firstErr <- err
+		}()
+		callCtx := <-started
+
+		secondResp := make(chan *Response, 1)
+		go func() {
+			resp, _ := h(context.Background(), NewRequestWithID("m", nil, "second"))
+			secondResp <- resp
+		}()
+		require.Eventually(t, func() bool {
+			coalescer.mutex.Lock()
+			defer coalescer.mutex.Unlock()
+			for _, call := range coalescer.calls {
+				return call.waiters == 2
+			}
+			return false
+		}, time.Second, time.Millisecond)
+
+		cancel()
+		assert.ErrorIs(t, <-firstErr, context.Canceled)
+		assert.NoError(t, callCtx.Err(), "shared call should continue for the remaining caller")
+
+		close(release)
+		resp := <-secondResp
+		require.NotNil(t, resp)
+		assert.Equal(t, "second", resp.IDString())
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("Shared call is canceled when all callers leave", func(t *testing.T) {
+		next, _, started := blockingHandler(make(chan struct{}))
+		coalescer := NewCoalescer()
+		h := coalescer.Wrap(next)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			_, err := h(ctx, NewRequest("m", nil))
+			done <- err
+		}()
+		callCtx := <-started
+		cancel()
+
+		assert.ErrorIs(t, <-done, context.Canceled)
+		<-callCtx.Done()
+		assert.Equal(t, 0, coalescer.InFlight())
+	})
+
+	t.Run("Errors are shared", func(t *testing.T) {
+		h := NewCoalescer().Wrap(func(context.Context, *Request) (*Response, error) {
+			return nil, errors.New("upstream down")
+		})
+		_, err := h(context.Background(), NewRequest("m", nil))
+		assert.EqualError(t, err, "upstream down")
+	})
+
+	t.Run("Notifications pass through", func(t *testing.T) {
+		next, calls := countingHandler(nil)
+		h := NewCoalescer().Wrap(next)
+		_, err := h(context.Background(), NewNotification("m", nil))
+		require.NoError(t, err)
+		assert.Equal(t, 1, *calls)
+	})
+	t.Run("Panics reach every caller", func(t *testing.T) {
+		release := make(chan struct{})
+		coalescer := NewCoalescer()
+		h := coalescer.Wrap(func(context.Context, *Request) (*Response, error) {
+			<-release
+			panic("boom")
+		})
+
+		const callers = 2
+		recovered := make(chan any, callers)
+		for range callers {
+			go func() {
+				defer func() { recovered <- recover() }()
+				_, _ = h(context.Background(), NewRequest("m", nil))
+			}()
+		}
+		assert.Eventually(t, func() bool {
+			coalescer.mutex.Lock()
+			defer coalescer.mutex.Unlock()
+			for _, call := range coalescer.calls {
+				return call.waiters == callers
+			}
+			return false
+		}, time.Second, time.Millisecond)
+
+		close(release)
+		for range callers {
+			value := <-recovered
+			err, ok := value.(error)
+			require.True(t, ok, "panic value should be an error")
+			assert.Contains(t, err.Error(), "boom")
+		}
+		assert.Zero(t, coalescer.InFlight())
+	})
+}
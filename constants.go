@@ -4,4 +4,6 @@ const (
 	defaultChunkSize = 16 * 1024
 	errEmptyData     = "empty data"
 	jsonRPCVersion   = "2.0"
+
+	errInvalidParamsType = "params field must be either an array, an object, or nil"
 )
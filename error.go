@@ -121,3 +121,118 @@ func (e *Error) Validate() error {
 	}
 	return nil
 }
+
+// Standard messages for the error codes defined by the JSON-RPC 2.0 specification.
+const (
+	msgParseError     = "Parse error"
+	msgInvalidRequest = "Invalid Request"
+	msgInvalidParams  = "Invalid params"
+	msgMethodNotFound = "Method not found"
+)
+
+// Descriptions of decode failures sent in the data member of error responses. Unlike the
+// underlying errors, they never quote the decoded input.
+const (
+	detailInvalidRequest = "invalid request object"
+	detailInvalidVersion = "jsonrpc field is required to be exactly \"2.0\""
+	detailMissingMethod  = "method field is required"
+	detailInvalidID      = "id field must be a string, a number, or null"
+	detailInvalidParams  = "params must be an array or object"
+)
+
+// DecodeError is returned when decoding a request fails. Code classifies the failure using the
+// JSON-RPC error code that should be sent in response:
+//   - ParseError: the data is not valid JSON
+//   - InvalidRequest: the data is valid JSON but not a valid Request object
+//   - InvalidParams: the params member is not a structured value
+//
+// Use errors.As to inspect a DecodeError, or NewDecodeErrorResponse to build the response directly.
+type DecodeError struct {
+	Code int
+	Err  error
+
+	// detail is a short description of the failure that is safe to send to clients
+	detail string
+}
+
+// Error returns the message of the underlying error.
+func (e *DecodeError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// newDecodeError wraps err in a DecodeError with the given code and client-facing detail.
+func newDecodeError(code int, detail string, err error) *DecodeError {
+	return &DecodeError{Code: code, Err: err, detail: detail}
+}
+
+// NewDecodeErrorResponse returns the error response to send for a request that failed to decode,
+// given the raw request bytes and the error returned by DecodeRequest. Returns nil if err is nil.
+//   - ParseError with a null id if data is not valid JSON
+//   - InvalidRequest with the id recovered from data if the envelope is invalid
+//   - InvalidParams with the id recovered from data if the params are invalid
+//
+// The error message is the standard message for the code, and the data member holds a short
+// description of the failure, which is omitted for parse errors. The detailed cause stays on the
+// DecodeError, since it may quote the input. Errors that are not a DecodeError are classified from
+// data and have no data member.
+//
+// Example usage:
+//
+//	req, err := jsonrpc.DecodeRequest(body)
+//	if err != nil {
+//		resp := jsonrpc.NewDecodeErrorResponse(body, err)
+//		// Write resp
+//	}
+func NewDecodeErrorResponse(data []byte, err error) *Response {
+	if err == nil {
+		return nil
+	}
+
+	rpcErr, id := classifyDecodeError(data, err)
+	return NewErrorResponse(id, rpcErr)
+}
+
+// classifyDecodeError converts a request decoding error into a JSON-RPC error and the id to
+// respond with. The id is recovered from data on a best-effort basis, except for parse errors,
+// which always use a null id.
+func classifyDecodeError(data []byte, err error) (*Error, ID) {
+	code := InvalidRequest
+	var detail any
+	var decodeErr *DecodeError
+	if errors.As(err, &decodeErr) {
+		code = decodeErr.Code
+		if decodeErr.detail != "" {
+			detail = decodeErr.detail
+		}
+	} else if !getSonicAPI().Valid(data) {
+		code = ParseError
+	}
+
+	id := NullID()
+	if code != ParseError {
+		id = extractElementID(data)
+	}
+
+	return &Error{
+		Code:    code,
+		Message: decodeErrorMessage(code),
+		Data:    detail,
+	}, id
+}
+
+// decodeErrorMessage returns the standard message for a decode error code.
+func decodeErrorMessage(code int) string {
+	switch code {
+	case ParseError:
+		return msgParseError
+	case InvalidParams:
+		return msgInvalidParams
+	default:
+		return msgInvalidRequest
+	}
+}
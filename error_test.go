@@ -3,6 +3,7 @@
 package jsonrpc
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -207,3 +208,113 @@ func TestError_Validate(t *testing.T) {
 		assert.Contains(t, err.Error(), "nil")
 	})
 }
+
+func TestDecodeError(t *testing.T) {
+	cases := []struct {
+		name string
+		data string
+		code int
+	}{
+		{name: "Invalid JSON", data: `{"jsonrpc":"2.0","id":1,`, code: ParseError},
+		{name: "Empty data", data: ` `, code: ParseError},
+		{name: "Not an object", data: `[1]`, code: InvalidRequest},
+		{name: "Wrong field type", data: `{"jsonrpc":"2.0","id":1,"method":5}`, code: InvalidRequest},
+		{name: "Wrong version", data: `{"jsonrpc":"1.0","id":1,"method":"m"}`, code: InvalidRequest},
+		{name: "Missing method", data: `{"jsonrpc":"2.0","id":1}`, code: InvalidRequest},
+		{name: "Invalid id", data: `{"jsonrpc":"2.0","id":true,"method":"m"}`, code: InvalidRequest},
+		{
+			name: "Invalid params",
+			data: `{"jsonrpc":"2.0","id":1,"method":"m","params":5}`,
+			code: InvalidParams,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := DecodeRequest([]byte(tc.data))
+			require.Error(t, err)
+
+			var decodeErr *DecodeError
+			require.True(t, errors.As(err, &decodeErr))
+			assert.Equal(t, tc.code, decodeErr.Code)
+			assert.NotNil(t, errors.Unwrap(decodeErr))
+		})
+	}
+}
+
+func TestNewDecodeErrorResponse(t *testing.T) {
+	respond := func(t *testing.T, data string) *Response {
+		t.Helper()
+		_, err := DecodeRequest([]byte(data))
+		require.Error(t, err)
+		resp := NewDecodeErrorResponse([]byte(data), err)
+		require.NotNil(t, resp)
+		require.NoError(t, resp.Validate())
+		return resp
+	}
+
+	t.Run("Parse error uses null id", func(t *testing.T) {
+		resp := respond(t, `{"jsonrpc":"2.0","id":7,"method":"m"`)
+
+		assert.True(t, resp.ID().IsNull())
+		assert.Equal(t, ParseError, resp.Err().Code)
+		assert.Equal(t, "Parse error", resp.Err().Message)
+		assert.Nil(t, resp.Err().Data)
+	})
+
+	t.Run("Invalid request recovers id", func(t *testing.T) {
+		resp := respond(t, `{"jsonrpc":"2.0","id":"abc"}`)
+
+		assert.Equal(t, StringID("abc"), resp.ID())
+		assert.Equal(t, InvalidRequest, resp.Err().Code)
+		assert.Equal(t, "Invalid Request", resp.Err().Message)
+		assert.Equal(t, "method field is required", resp.Err().Data)
+	})
+
+	t.Run("Invalid params recovers id", func(t *testing.T) {
+		resp := respond(t, `{"jsonrpc":"2.0","id":3,"method":"m","params":"x"}`)
+
+		assert.Equal(t, Int64ID(3), resp.ID())
+		assert.Equal(t, InvalidParams, resp.Err().Code)
+		assert.Equal(t, "Invalid params", resp.Err().Message)
+		assert.Equal(t, "params must be an array or object", resp.Err().Data)
+	})
+
+	t.Run("Data does not quote the input", func(t *testing.T) {
+		for _, data := range []string{
+			`{"jsonrpc":"2.0","id":1,"method":5}`,
+			`{"jsonrpc":"2.0","id":[{"secret":1}],"method":"m"}`,
+			`{"jsonrpc":"2.0","id":1,"method":"m","params":"secret"}`,
+		} {
+			resp := respond(t, data)
+			detail, ok := resp.Err().Data.(string)
+			require.True(t, ok, data)
+			assert.NotContains(t, detail, "secret", data)
+			assert.NotContains(t, detail, "requestAux", data)
+
+			_, err := DecodeRequest([]byte(data))
+			assert.NotEqual(t, detail, err.Error(), "the cause should stay on the DecodeError")
+		}
+	})
+
+	t.Run("Invalid request without id", func(t *testing.T) {
+		resp := respond(t, `{"foo":"boo"}`)
+
+		assert.True(t, resp.ID().IsNull())
+		assert.Equal(t, InvalidRequest, resp.Err().Code)
+	})
+
+	t.Run("Untyped errors are classified from data", func(t *testing.T) {
+		resp := NewDecodeErrorResponse([]byte(`{`), errors.New("boom"))
+		assert.Equal(t, ParseError, resp.Err().Code)
+		assert.Nil(t, resp.Err().Data)
+
+		resp = NewDecodeErrorResponse([]byte(`{"id":2}`), errors.New("boom"))
+		assert.Equal(t, InvalidRequest, resp.Err().Code)
+		assert.Equal(t, Int64ID(2), resp.ID())
+	})
+
+	t.Run("Nil error", func(t *testing.T) {
+		assert.Nil(t, NewDecodeErrorResponse([]byte(`{}`), nil))
+	})
+}
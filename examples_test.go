@@ -10,8 +10,8 @@ func ExampleRequest_params_positional() {
 	req := NewRequest("subtract", []any{42, 23})
 
 	fmt.Printf("Method: %s\n", req.Method)
-	fmt.Printf("Params type: %T\n", req.Params)
-	fmt.Printf("Params: %v\n", req.Params)
+	fmt.Printf("Params type: %T\n", req.Params())
+	fmt.Printf("Params: %v\n", req.Params())
 	// Output:
 	// Method: subtract
 	// Params type: []interface {}
@@ -28,7 +28,7 @@ func ExampleRequest_params_named() {
 	})
 
 	fmt.Printf("Method: %s\n", req.Method)
-	fmt.Printf("Params type: %T\n", req.Params)
+	fmt.Printf("Params type: %T\n", req.Params())
 	// Output:
 	// Method: updateUser
 	// Params type: map[string]interface {}
@@ -68,7 +68,7 @@ func ExampleRequest_params_nil() {
 	req := NewRequest("getServerTime", nil)
 
 	fmt.Printf("Method: %s\n", req.Method)
-	fmt.Printf("Params: %v\n", req.Params)
+	fmt.Printf("Params: %v\n", req.Params())
 	// Output:
 	// Method: getServerTime
 	// Params: <nil>
@@ -0,0 +1,22 @@
+package jsonrpc
+
+import "context"
+
+// HandlerFunc handles a request and returns its response. The same signature is used on both
+// sides of a connection: a server implements it to serve requests, and a client implements it to
+// send requests to a remote endpoint. Components such as ResponseCache wrap a HandlerFunc, which
+// makes them usable for serving and calling alike.
+//
+// For notifications, a HandlerFunc should return a nil response.
+type HandlerFunc func(ctx context.Context, req *Request) (*Response, error)
+
+// Middleware wraps a HandlerFunc to add behavior before or after it is called.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Chain returns h wrapped by the middlewares, with the first middleware as the outermost one.
+func Chain(h HandlerFunc, middlewares ...Middleware) HandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
@@ -0,0 +1,30 @@
+package jsonrpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChain(t *testing.T) {
+	var order []string
+	record := func(name string) Middleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(ctx context.Context, req *Request) (*Response, error) {
+				order = append(order, name)
+				return next(ctx, req)
+			}
+		}
+	}
+	h := Chain(func(_ context.Context, req *Request) (*Response, error) {
+		order = append(order, "handler")
+		return NewResponse(req.ID, "ok")
+	}, record("outer"), record("inner"))
+
+	resp, err := h(context.Background(), NewRequest("m", nil))
+	require.NoError(t, err)
+	assert.JSONEq(t, `"ok"`, string(resp.RawResult()))
+	assert.Equal(t, []string{"outer", "inner", "handler"}, order)
+}
@@ -0,0 +1,259 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHedgePercentile is the default latency percentile after which a request is hedged.
+	DefaultHedgePercentile = 0.95
+
+	// DefaultHedgeDelay is the default hedge delay of methods with too few latency samples.
+	DefaultHedgeDelay = 100 * time.Millisecond
+
+	// DefaultHedgeMinSamples is the default number of latency samples required before the hedge
+	// delay of a method is derived from its latency distribution.
+	DefaultHedgeMinSamples = 20
+
+	// DefaultHedgeWindow is the default number of latency samples kept per method.
+	DefaultHedgeWindow = 200
+)
+
+// HedgeConfig configures a Hedger.
+type HedgeConfig struct {
+	// Upstreams receive the requests. The first attempt of a request is sent to the first
+	// upstream and the hedge to the second. With a single upstream, e.g. an UpstreamPool, both
+	// are sent to it. At least one is required.
+	Upstreams []Upstream
+
+	// Methods are the methods that are hedged, as exact names or patterns in the syntax of
+	// path.Match. Only methods without side effects should be hedged, as both attempts may be
+	// executed. Other requests are sent to the first upstream only.
+	Methods []string
+
+	// Percentile of the latency distribution of a method after which a request is hedged, from
+	// 0 to 1. Defaults to DefaultHedgePercentile.
+	Percentile float64
+
+	// DefaultDelay is the hedge delay of methods with fewer than MinSamples latency samples.
+	// Defaults to DefaultHedgeDelay.
+	DefaultDelay time.Duration
+
+	// MinDelay and MaxDelay bound the hedge delay derived from latency samples. MaxDelay is
+	// unbounded if zero.
+	MinDelay time.Duration
+	MaxDelay time.Duration
+
+	// MinSamples is the number of latency samples required before the hedge delay of a method is
+	// derived from its latency distribution. Defaults to DefaultHedgeMinSamples.
+	MinSamples int
+
+	// Window is the number of most recent latency samples kept per method. Defaults to
+	// DefaultHedgeWindow.
+	Window int
+}
+
+// Hedger is an Upstream that reduces tail latency by hedging: if a request has not been answered
+// within a delay derived from the latency distribution of its method, the same request is sent a
+// second time, and whichever response arrives first is returned while the other attempt is
+// canceled. If the first attempt fails before the delay, the hedge is sent right away. Error
+// responses count as answers, while transport errors do not.
+//
+// The latency distribution samples the first attempts. If the hedge wins, the time until then is
+// sampled as a lower bound of the latency of the first attempt, so that slow first attempts keep
+// raising the delay instead of the fast hedges lowering it. Failed first attempts are not sampled.
+//
+// Responses are returned with the ID of the request, whichever attempt they answer. Batches are
+// sent to the first upstream without hedging.
+//
+// A Hedger is safe for concurrent use.
+//
+// Example usage:
+//
+//	hedger, err := jsonrpc.NewHedger(jsonrpc.HedgeConfig{
+//		Upstreams: []jsonrpc.Upstream{primary, secondary},
+//		Methods:   []string{"eth_getBalance", "eth_call"},
+//	})
+//	resp, err := hedger.Call(ctx, req)
+type Hedger struct {
+	upstreams    []Upstream
+	methods      []string
+	percentile   float64
+	defaultDelay time.Duration
+	minDelay     time.Duration
+	maxDelay     time.Duration
+	minSamples   int
+	window       int
+
+	mutex     sync.Mutex
+	latencies map[string]*latencyWindow
+}
+
+// latencyWindow is a ring buffer of the most recent latency samples of a method.
+type latencyWindow struct {
+	samples []time.Duration
+	next    int
+}
+
+// hedgeResult is the outcome of an attempt of a hedged request.
+type hedgeResult struct {
+	resp    *Response
+	err     error
+	primary bool
+}
+
+// NewHedger creates a Hedger with the given configuration.
+func NewHedger(config HedgeConfig) (*Hedger, error) {
+	if len(config.Upstreams) == 0 {
+		return nil, errors.New("hedger requires at least one upstream")
+	}
+	if slices.Contains(config.Upstreams, nil) {
+		return nil, errors.New("upstream cannot be nil")
+	}
+	if config.Percentile < 0 || config.Percentile > 1 {
+		return nil, errors.New("percentile must be between 0 and 1")
+	}
+
+	hedger := &Hedger{
+		upstreams:    slices.Clone(config.Upstreams),
+		methods:      slices.Clone(config.Methods),
+		percentile:   config.Percentile,
+		defaultDelay: positiveOr(config.DefaultDelay, DefaultHedgeDelay),
+		minDelay:     config.MinDelay,
+		maxDelay:     config.MaxDelay,
+		minSamples:   positiveOr(config.MinSamples, DefaultHedgeMinSamples),
+		window:       positiveOr(config.Window, DefaultHedgeWindow),
+		latencies:    make(map[string]*latencyWindow),
+	}
+	if hedger.percentile == 0 {
+		hedger.percentile = DefaultHedgePercentile
+	}
+
+	return hedger, nil
+}
+
+// Call sends the request to the first upstream, and hedges it as described for Hedger if its
+// method is one of the hedged methods. If both attempts fail, the outcome of the last one is
+// returned.
+func (h *Hedger) Call(ctx context.Context, req *Request) (*Response, error) {
+	if req == nil {
+		return nil, errors.New("request cannot be nil")
+	}
+	if req.IsNotification() || !matchMethod(h.methods, req.Method) {
+		return h.upstreams[0].Call(ctx, req)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult, 2)
+	send := func(upstream Upstream, primary bool) {
+		go func() {
+			resp, err := upstream.Call(ctx, req)
+			results <- hedgeResult{resp: resp, err: err, primary: primary}
+		}()
+	}
+
+	start := time.Now()
+	send(h.upstreams[0], true)
+	pending := 1
+	timer := time.NewTimer(h.Delay(req.Method))
+	defer timer.Stop()
+	hedge := timer.C
+
+	var last hedgeResult
+	primaryFailed := false
+	for pending > 0 {
+		select {
+		case <-hedge:
+			hedge = nil
+			send(h.upstreams[1%len(h.upstreams)], false)
+			pending++
+		case result := <-results:
+			pending--
+			if result.err == nil && result.resp != nil {
+				if !primaryFailed {
+					// The latency of the primary, censored at this point if the hedge won, so
+					// that slow primaries keep counting towards the percentile
+					h.record(req.Method, time.Since(start))
+				}
+				return withRequestID(result.resp, req)
+			}
+			last = result
+			primaryFailed = primaryFailed || result.primary
+			if hedge != nil {
+				// The first attempt failed before the delay, so hedge right away
+				hedge = nil
+				send(h.upstreams[1%len(h.upstreams)], false)
+				pending++
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return last.resp, last.err
+}
+
+// CallBatch sends the requests to the first upstream without hedging.
+func (h *Hedger) CallBatch(ctx context.Context, reqs []*Request) ([]*Response, error) {
+	return h.upstreams[0].CallBatch(ctx, reqs)
+}
+
+// Delay returns the current hedge delay of method: the configured percentile of its recent
+// latencies bounded by MinDelay and MaxDelay, or DefaultDelay if there are too few samples.
+func (h *Hedger) Delay(method string) time.Duration {
+	h.mutex.Lock()
+	window := h.latencies[method]
+	var samples []time.Duration
+	if window != nil && len(window.samples) >= h.minSamples {
+		samples = slices.Clone(window.samples)
+	}
+	h.mutex.Unlock()
+
+	if samples == nil {
+		return h.defaultDelay
+	}
+
+	slices.Sort(samples)
+	index := int(math.Ceil(h.percentile*float64(len(samples)))) - 1
+	delay := samples[max(index, 0)]
+
+	delay = max(delay, h.minDelay)
+	if h.maxDelay > 0 {
+		delay = min(delay, h.maxDelay)
+	}
+	return delay
+}
+
+// record adds a latency sample of method.
+func (h *Hedger) record(method string, latency time.Duration) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	window := h.latencies[method]
+	if window == nil {
+		window = &latencyWindow{samples: make([]time.Duration, 0, h.window)}
+		h.latencies[method] = window
+	}
+
+	if len(window.samples) < h.window {
+		window.samples = append(window.samples, latency)
+		return
+	}
+	window.samples[window.next] = latency
+	window.next = (window.next + 1) % h.window
+}
+
+// withRequestID returns resp with the ID of req, cloning it only if the IDs differ.
+func withRequestID(resp *Response, req *Request) (*Response, error) {
+	if resp.ID() == req.ID {
+		return resp, nil
+	}
+	return resp.WithID(req.ID)
+}
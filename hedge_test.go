@@ -0,0 +1,180 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHedger(t *testing.T) {
+	_, err := NewHedger(HedgeConfig{})
+	assert.Error(t, err)
+	_, err = NewHedger(HedgeConfig{Upstreams: []Upstream{nil}})
+	assert.Error(t, err)
+	_, err = NewHedger(HedgeConfig{Upstreams: []Upstream{&fakeUpstream{}}, Percentile: 2})
+	assert.Error(t, err)
+
+	hedger, err := NewHedger(HedgeConfig{Upstreams: []Upstream{&fakeUpstream{}}})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultHedgePercentile, hedger.percentile)
+	assert.Equal(t, DefaultHedgeDelay, hedger.Delay("m"))
+}
+
+func TestHedger_Call(t *testing.T) {
+	t.Run("Slow primary is hedged", func(t *testing.T) {
+		slow := &fakeUpstream{name: "slow", delay: time.Second}
+		fast := &fakeUpstream{name: "fast"}
+		hedger, err := NewHedger(HedgeConfig{
+			Upstreams:    []Upstream{slow, fast},
+			Methods:      []string{"eth_*"},
+			DefaultDelay: 10 * time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		start := time.Now()
+		resp, err := hedger.Call(context.Background(), NewRequestWithID("eth_call", nil, 1))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"fast"}, resultNames(t, []*Response{resp}))
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.Eventually(t, func() bool { return slow.canceled.Load() == 1 },
+			time.Second, time.Millisecond, "losing attempt should be canceled")
+	})
+
+	t.Run("Fast primary is not hedged", func(t *testing.T) {
+		primary := &fakeUpstream{name: "primary"}
+		secondary := &fakeUpstream{name: "secondary"}
+		hedger, err := NewHedger(HedgeConfig{
+			Upstreams:    []Upstream{primary, secondary},
+			Methods:      []string{"m"},
+			DefaultDelay: time.Second,
+		})
+		require.NoError(t, err)
+
+		resp, err := hedger.Call(context.Background(), NewRequest("m", nil))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"primary"}, resultNames(t, []*Response{resp}))
+		assert.Zero(t, secondary.calls.Load())
+	})
+
+	t.Run("Failed primary is hedged right away", func(t *testing.T) {
+		primary := &fakeUpstream{err: errors.New("reset")}
+		secondary := &fakeUpstream{name: "secondary"}
+		hedger, err := NewHedger(HedgeConfig{
+			Upstreams:    []Upstream{primary, secondary},
+			Methods:      []string{"m"},
+			DefaultDelay: time.Minute,
+		})
+		require.NoError(t, err)
+
+		resp, err := hedger.Call(context.Background(), NewRequest("m", nil))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"secondary"}, resultNames(t, []*Response{resp}))
+
+		secondary.setErr(errors.New("timeout"))
+		_, err = hedger.Call(context.Background(), NewRequest("m", nil))
+		assert.Error(t, err)
+	})
+
+	t.Run("Responses carry the request ID", func(t *testing.T) {
+		upstream := &fakeUpstream{name: "u", id: "upstream-id"}
+		hedger, err := NewHedger(HedgeConfig{Upstreams: []Upstream{upstream}, Methods: []string{"m"}})
+		require.NoError(t, err)
+
+		resp, err := hedger.Call(context.Background(), NewRequestWithID("m", nil, "caller-id"))
+		require.NoError(t, err)
+		assert.Equal(t, "caller-id", resp.IDString())
+	})
+
+	t.Run("Other methods are not hedged", func(t *testing.T) {
+		slow := &fakeUpstream{name: "slow", delay: 50 * time.Millisecond}
+		other := &fakeUpstream{name: "other"}
+		hedger, err := NewHedger(HedgeConfig{
+			Upstreams:    []Upstream{slow, other},
+			Methods:      []string{"eth_call"},
+			DefaultDelay: time.Millisecond,
+		})
+		require.NoError(t, err)
+
+		resp, err := hedger.Call(context.Background(), NewRequest("eth_sendRawTransaction", nil))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"slow"}, resultNames(t, []*Response{resp}))
+		assert.Zero(t, other.calls.Load())
+
+		resps, err := hedger.CallBatch(context.Background(), []*Request{
+			NewRequest("eth_call", nil),
+			NewRequest("eth_call", nil),
+			NewNotification("eth_call", nil),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"slow", "slow"}, resultNames(t, resps))
+		assert.Equal(t, []int{3}, slow.batchSizes())
+	})
+
+	t.Run("Canceled context", func(t *testing.T) {
+		slow := &fakeUpstream{name: "slow", delay: time.Minute}
+		hedger, err := NewHedger(HedgeConfig{Upstreams: []Upstream{slow}, Methods: []string{"m"}})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		_, err = hedger.Call(ctx, NewRequest("m", nil))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestHedger_DelayDoesNotDrift(t *testing.T) {
+	slow := &fakeUpstream{name: "slow", delay: time.Second}
+	fast := &fakeUpstream{name: "fast", delay: time.Millisecond}
+	hedger, err := NewHedger(HedgeConfig{
+		Upstreams:    []Upstream{slow, fast},
+		Methods:      []string{"m"},
+		DefaultDelay: 20 * time.Millisecond,
+		MinSamples:   5,
+		Window:       5,
+	})
+	require.NoError(t, err)
+
+	for range 10 {
+		resp, err := hedger.Call(context.Background(), NewRequest("m", nil))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"fast"}, resultNames(t, []*Response{resp}))
+	}
+	// Sampling the fast hedges would bring the delay down to about a millisecond
+	assert.GreaterOrEqual(t, hedger.Delay("m"), 20*time.Millisecond)
+}
+
+func TestHedger_Delay(t *testing.T) {
+	hedger, err := NewHedger(HedgeConfig{
+		Upstreams:  []Upstream{&fakeUpstream{}},
+		Percentile: 0.9,
+		MinSamples: 10,
+		Window:     10,
+		MinDelay:   2 * time.Millisecond,
+		MaxDelay:   50 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	for i := 1; i <= 9; i++ {
+		hedger.record("m", time.Duration(i)*time.Millisecond)
+	}
+	assert.Equal(t, DefaultHedgeDelay, hedger.Delay("m"), "too few samples")
+
+	hedger.record("m", 10*time.Millisecond)
+	assert.Equal(t, 9*time.Millisecond, hedger.Delay("m"))
+
+	// The window keeps the most recent samples only
+	for range 10 {
+		hedger.record("m", time.Second)
+	}
+	assert.Equal(t, 50*time.Millisecond, hedger.Delay("m"), "bounded by MaxDelay")
+
+	for range 10 {
+		hedger.record("m", time.Microsecond)
+	}
+	assert.Equal(t, 2*time.Millisecond, hedger.Delay("m"), "bounded by MinDelay")
+	assert.Equal(t, DefaultHedgeDelay, hedger.Delay("other"))
+}
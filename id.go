@@ -0,0 +1,431 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// IDKind describes which kind of value a JSON-RPC ID holds.
+type IDKind uint8
+
+const (
+	// IDKindAbsent means the id member is not present, e.g. for notifications.
+	IDKindAbsent IDKind = iota
+
+	// IDKindNull means the id member is present with a null value.
+	IDKindNull
+
+	// IDKindNumber means the id is a JSON number, integer or fractional.
+	IDKindNumber
+
+	// IDKindString means the id is a JSON string.
+	IDKindString
+
+	// idKindInvalid marks an ID created from an unsupported value. It is never produced by
+	// decoding, only by constructors that cannot return an error, and fails validation.
+	idKindInvalid IDKind = math.MaxUint8
+)
+
+const errInvalidIDType = "id field must be a string or a number"
+
+// ID is a JSON-RPC 2.0 request or response identifier.
+//
+// An ID keeps the exact JSON encoding it was decoded from or created with, so that numbers of any
+// size and format round-trip unchanged, e.g. 18446744073709551616 or 1.0. It also distinguishes an
+// absent id (notifications) from an explicit null.
+//
+// ID is an immutable value type. Two IDs are equal with == when they are of the same kind and have
+// the same raw encoding, which makes ID usable as a map key. The zero value is an absent ID.
+type ID struct {
+	raw  string
+	kind IDKind
+}
+
+// StringID returns an ID holding the given string.
+func StringID(s string) ID {
+	return ID{raw: string(appendJSONString(nil, s)), kind: IDKindString}
+}
+
+// Int64ID returns an ID holding the given integer.
+func Int64ID(n int64) ID {
+	return ID{raw: strconv.FormatInt(n, decimalBase), kind: IDKindNumber}
+}
+
+// Uint64ID returns an ID holding the given unsigned integer.
+func Uint64ID(n uint64) ID {
+	return ID{raw: strconv.FormatUint(n, decimalBase), kind: IDKindNumber}
+}
+
+// Float64ID returns an ID holding the given fractional number. Whole numbers are encoded with a
+// trailing ".0" to preserve their fractional type. NaN and infinite values yield an invalid ID.
+func Float64ID(f float64) ID {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return ID{kind: idKindInvalid}
+	}
+	return ID{raw: formatFloat64ID(f), kind: IDKindNumber}
+}
+
+// NullID returns an ID holding an explicit null.
+func NullID() ID {
+	return ID{raw: "null", kind: IDKindNull}
+}
+
+// NewID converts a Go value to an ID. Supported values are nil (absent ID), ID, strings, integer
+// and float types, json.Number, json.RawMessage and *big.Int.
+func NewID(v any) (ID, error) {
+	switch id := v.(type) {
+	case nil:
+		return ID{}, nil
+	case ID:
+		return id, id.validate()
+	case string:
+		return StringID(id), nil
+	case int:
+		return Int64ID(int64(id)), nil
+	case int8:
+		return Int64ID(int64(id)), nil
+	case int16:
+		return Int64ID(int64(id)), nil
+	case int32:
+		return Int64ID(int64(id)), nil
+	case int64:
+		return Int64ID(id), nil
+	case uint:
+		return Uint64ID(uint64(id)), nil
+	case uint8:
+		return Uint64ID(uint64(id)), nil
+	case uint16:
+		return Uint64ID(uint64(id)), nil
+	case uint32:
+		return Uint64ID(uint64(id)), nil
+	case uint64:
+		return Uint64ID(id), nil
+	case float32:
+		return newFloatID(float64(id))
+	case float64:
+		return newFloatID(id)
+	case json.Number:
+		return parseNumberID(string(id))
+	case json.RawMessage:
+		return ParseID(id)
+	case *big.Int:
+		if id == nil {
+			return ID{}, nil
+		}
+		return ID{raw: id.String(), kind: IDKindNumber}, nil
+	default:
+		return ID{kind: idKindInvalid}, errors.New(errInvalidIDType)
+	}
+}
+
+// ParseID parses the raw JSON encoding of an id member. Empty input yields an absent ID, null
+// yields a null ID, and strings and numbers are kept in their exact raw form. Any other JSON value
+// results in an error.
+func ParseID(raw []byte) (ID, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if len(trimmed) == 0 {
+		return ID{}, nil
+	}
+
+	switch c := trimmed[0]; {
+	case c == 'n':
+		if string(trimmed) != "null" {
+			return ID{}, fmt.Errorf("invalid id field: %s", trimmed)
+		}
+		return NullID(), nil
+	case c == '"':
+		var s string
+		if err := getSonicAPI().Unmarshal(trimmed, &s); err != nil {
+			return ID{}, fmt.Errorf("invalid id field: %w", err)
+		}
+		return ID{raw: string(trimmed), kind: IDKindString}, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		return parseNumberID(string(trimmed))
+	default:
+		return ID{}, errors.New(errInvalidIDType)
+	}
+}
+
+// toID converts a Go value to an ID, returning an invalid ID for unsupported values. It is used by
+// constructors without an error return, leaving the error to be reported by Validate.
+func toID(v any) ID {
+	id, err := NewID(v)
+	if err != nil {
+		return ID{kind: idKindInvalid}
+	}
+	return id
+}
+
+// newFloatID returns a number ID for a float, rejecting NaN and infinite values.
+func newFloatID(f float64) (ID, error) {
+	id := Float64ID(f)
+	if err := id.validate(); err != nil {
+		return id, err
+	}
+	return id, nil
+}
+
+// parseNumberID returns a number ID for a raw JSON number.
+func parseNumberID(s string) (ID, error) {
+	if !isJSONNumber(s) {
+		return ID{}, fmt.Errorf("invalid id field: %q is not a valid JSON number", s)
+	}
+	return ID{raw: s, kind: IDKindNumber}, nil
+}
+
+// Kind returns the kind of value the ID holds.
+func (id ID) Kind() IDKind {
+	return id.kind
+}
+
+// IsAbsent returns true if the id member is not present.
+func (id ID) IsAbsent() bool {
+	return id.kind == IDKindAbsent
+}
+
+// IsNull returns true if the id member is an explicit null.
+func (id ID) IsNull() bool {
+	return id.kind == IDKindNull
+}
+
+// IsNumber returns true if the ID is a JSON number.
+func (id ID) IsNumber() bool {
+	return id.kind == IDKindNumber
+}
+
+// IsString returns true if the ID is a JSON string.
+func (id ID) IsString() bool {
+	return id.kind == IDKindString
+}
+
+// Raw returns the exact JSON encoding of the ID, or nil for an absent or invalid ID.
+func (id ID) Raw() json.RawMessage {
+	if id.raw == "" {
+		return nil
+	}
+	return json.RawMessage(id.raw)
+}
+
+// String returns the string form of the ID. For string IDs this is the unquoted value, for number
+// IDs the number exactly as encoded. Absent and null IDs yield an empty string.
+// Note: implements the fmt.Stringer interface.
+func (id ID) String() string {
+	switch id.kind {
+	case IDKindString:
+		// Fast path for strings without escape sequences
+		if strings.IndexByte(id.raw, '\\') < 0 {
+			return id.raw[1 : len(id.raw)-1]
+		}
+		var s string
+		if err := getSonicAPI().UnmarshalFromString(id.raw, &s); err != nil {
+			return ""
+		}
+		return s
+	case IDKindNumber:
+		return id.raw
+	default:
+		return ""
+	}
+}
+
+// Int64 returns the ID as an int64. It fails if the ID is not a number, is not an exact integer, or
+// overflows int64. Numbers with a zero fraction or an exponent, e.g. 1.0 or 1e3, are accepted.
+func (id ID) Int64() (int64, error) {
+	if id.kind != IDKindNumber {
+		return 0, errors.New("id is not a number")
+	}
+	if n, err := strconv.ParseInt(id.raw, decimalBase, 64); err == nil {
+		return n, nil
+	}
+
+	n, err := id.BigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !n.IsInt64() {
+		return 0, fmt.Errorf("id %s overflows int64", id.raw)
+	}
+	return n.Int64(), nil
+}
+
+// Uint64 returns the ID as a uint64. It fails if the ID is not a number, is not an exact
+// non-negative integer, or overflows uint64.
+func (id ID) Uint64() (uint64, error) {
+	if id.kind != IDKindNumber {
+		return 0, errors.New("id is not a number")
+	}
+	if n, err := strconv.ParseUint(id.raw, decimalBase, 64); err == nil {
+		return n, nil
+	}
+
+	n, err := id.BigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !n.IsUint64() {
+		return 0, fmt.Errorf("id %s overflows uint64", id.raw)
+	}
+	return n.Uint64(), nil
+}
+
+// BigInt returns the ID as an arbitrary precision integer. It fails if the ID is not a number or is
+// not an exact integer.
+func (id ID) BigInt() (*big.Int, error) {
+	if id.kind != IDKindNumber {
+		return nil, errors.New("id is not a number")
+	}
+
+	rat, ok := new(big.Rat).SetString(id.raw)
+	if !ok {
+		return nil, fmt.Errorf("invalid number id %s", id.raw)
+	}
+	if !rat.IsInt() {
+		return nil, fmt.Errorf("id %s is not an integer", id.raw)
+	}
+	return rat.Num(), nil
+}
+
+// Float64 returns the ID as a float64. Precision may be lost for large integers.
+func (id ID) Float64() (float64, error) {
+	if id.kind != IDKindNumber {
+		return 0, errors.New("id is not a number")
+	}
+	return strconv.ParseFloat(id.raw, 64)
+}
+
+// Value returns the ID as a plain Go value: a string for string IDs, an int64 for integer IDs that
+// fit, a float64 for other numbers, and nil for absent and null IDs.
+func (id ID) Value() any {
+	switch id.kind {
+	case IDKindString:
+		return id.String()
+	case IDKindNumber:
+		if n, err := strconv.ParseInt(id.raw, decimalBase, 64); err == nil {
+			return n
+		}
+		f, err := strconv.ParseFloat(id.raw, 64)
+		if err != nil {
+			return nil
+		}
+		return f
+	default:
+		return nil
+	}
+}
+
+// Equal returns true if both IDs are of the same kind and have the same raw encoding.
+func (id ID) Equal(other ID) bool {
+	return id == other
+}
+
+// MarshalJSON returns the raw encoding of the ID. Absent IDs are encoded as null.
+func (id ID) MarshalJSON() ([]byte, error) {
+	if err := id.validate(); err != nil {
+		return nil, err
+	}
+	return id.appendJSON(nil), nil
+}
+
+// UnmarshalJSON parses the ID from its raw JSON encoding.
+func (id *ID) UnmarshalJSON(data []byte) error {
+	parsed, err := ParseID(data)
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
+
+// appendJSON appends the raw encoding of the ID to dst, using null for absent IDs.
+func (id ID) appendJSON(dst []byte) []byte {
+	return append(dst, id.rawOrNull()...)
+}
+
+// rawOrNull returns the raw encoding of the ID, using null for absent IDs.
+func (id ID) rawOrNull() string {
+	if id.raw == "" {
+		return "null"
+	}
+	return id.raw
+}
+
+// validate returns an error if the ID was created from an unsupported value.
+func (id ID) validate() error {
+	if id.kind == idKindInvalid {
+		return errors.New(errInvalidIDType)
+	}
+	return nil
+}
+
+// isJSONNumber reports whether s is a valid JSON number per RFC 8259.
+func isJSONNumber(s string) bool {
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+
+	// Integer part: a single zero or a non-zero digit followed by digits
+	switch {
+	case i < len(s) && s[i] == '0':
+		i++
+	case i < len(s) && s[i] >= '1' && s[i] <= '9':
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+	default:
+		return false
+	}
+
+	// Optional fraction
+	if i < len(s) && s[i] == '.' {
+		i++
+		if i >= len(s) || !isDigit(s[i]) {
+			return false
+		}
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+	}
+
+	// Optional exponent
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if i >= len(s) || !isDigit(s[i]) {
+			return false
+		}
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+	}
+
+	return i == len(s)
+}
+
+// isDigit reports whether c is an ASCII digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// formatIDForString formats an ID for String representations of requests and responses, using
+// <nil> for absent and null IDs.
+func formatIDForString(id ID) string {
+	if isNullOrAbsentID(id) || id.raw == "" {
+		return "<nil>"
+	}
+	return id.String()
+}
+
+// isNullOrAbsentID returns true if the ID is absent or null, which are encoded the same way in
+// responses.
+func isNullOrAbsentID(id ID) bool {
+	return id.kind == IDKindAbsent || id.kind == IDKindNull
+}
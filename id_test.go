@@ -0,0 +1,228 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseID(t *testing.T) {
+	t.Run("Valid IDs", func(t *testing.T) {
+		cases := []struct {
+			name   string
+			raw    string
+			kind   IDKind
+			str    string
+			rawOut string
+		}{
+			{name: "Absent", raw: ``, kind: IDKindAbsent, str: "", rawOut: ""},
+			{name: "Null", raw: `null`, kind: IDKindNull, str: "", rawOut: "null"},
+			{name: "Integer", raw: `42`, kind: IDKindNumber, str: "42", rawOut: "42"},
+			{name: "Negative", raw: `-7`, kind: IDKindNumber, str: "-7", rawOut: "-7"},
+			{name: "Fraction", raw: `1.0`, kind: IDKindNumber, str: "1.0", rawOut: "1.0"},
+			{name: "Exponent", raw: `1e3`, kind: IDKindNumber, str: "1e3", rawOut: "1e3"},
+			{
+				name:   "Beyond uint64",
+				raw:    `18446744073709551616`,
+				kind:   IDKindNumber,
+				str:    "18446744073709551616",
+				rawOut: "18446744073709551616",
+			},
+			{name: "String", raw: `"abc"`, kind: IDKindString, str: "abc", rawOut: `"abc"`},
+			{name: "Empty string", raw: `""`, kind: IDKindString, str: "", rawOut: `""`},
+			{name: "Escaped", raw: `"a\"b"`, kind: IDKindString, str: `a"b`, rawOut: `"a\"b"`},
+			{name: "Whitespace", raw: ` 5 `, kind: IDKindNumber, str: "5", rawOut: "5"},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				id, err := ParseID([]byte(tc.raw))
+				require.NoError(t, err)
+				assert.Equal(t, tc.kind, id.Kind())
+				assert.Equal(t, tc.str, id.String())
+				assert.Equal(t, tc.rawOut, string(id.Raw()))
+			})
+		}
+	})
+
+	t.Run("Invalid IDs", func(t *testing.T) {
+		for _, raw := range []string{
+			`true`, `{}`, `[]`, `nul`, `01`, `1.`, `-`, `1e`, `"unterminated`, `0x10`,
+		} {
+			_, err := ParseID([]byte(raw))
+			assert.Error(t, err, "raw %q should be rejected", raw)
+		}
+	})
+}
+
+func TestNewID(t *testing.T) {
+	t.Run("Supported values", func(t *testing.T) {
+		cases := []struct {
+			name     string
+			value    any
+			expected ID
+		}{
+			{name: "Nil", value: nil, expected: ID{}},
+			{name: "ID", value: StringID("x"), expected: StringID("x")},
+			{name: "String", value: "x", expected: StringID("x")},
+			{name: "Int", value: 1, expected: Int64ID(1)},
+			{name: "Int32", value: int32(-2), expected: Int64ID(-2)},
+			{name: "Uint64", value: uint64(math.MaxUint64), expected: Uint64ID(math.MaxUint64)},
+			{name: "Float64", value: 1.5, expected: Float64ID(1.5)},
+			{name: "Whole float64", value: float64(2), expected: Float64ID(2)},
+			{name: "JSON number", value: json.Number("12.50"), expected: mustParseID(t, `12.50`)},
+			{name: "Raw message", value: json.RawMessage(`"r"`), expected: StringID("r")},
+			{
+				name:     "Big int",
+				value:    new(big.Int).Lsh(big.NewInt(1), 70),
+				expected: mustParseID(t, `1180591620717411303424`),
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				id, err := NewID(tc.value)
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, id)
+			})
+		}
+	})
+
+	t.Run("Unsupported values", func(t *testing.T) {
+		for _, value := range []any{true, []int{1}, struct{}{}, math.NaN(), math.Inf(1)} {
+			id, err := NewID(value)
+			assert.Error(t, err, "value %v should be rejected", value)
+			assert.Error(t, id.validate())
+		}
+	})
+}
+
+func TestID_NumericAccess(t *testing.T) {
+	t.Run("Int64", func(t *testing.T) {
+		n, err := mustParseID(t, `9007199254740993`).Int64()
+		require.NoError(t, err)
+		assert.Equal(t, int64(9007199254740993), n, "integers above 2^53 must be exact")
+
+		n, err = mustParseID(t, `1.0`).Int64()
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), n)
+
+		n, err = mustParseID(t, `2e3`).Int64()
+		require.NoError(t, err)
+		assert.Equal(t, int64(2000), n)
+
+		_, err = mustParseID(t, `1.5`).Int64()
+		assert.Error(t, err)
+		_, err = mustParseID(t, `9223372036854775808`).Int64()
+		assert.Error(t, err)
+		_, err = StringID("1").Int64()
+		assert.Error(t, err)
+	})
+
+	t.Run("Uint64", func(t *testing.T) {
+		n, err := mustParseID(t, `18446744073709551615`).Uint64()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(math.MaxUint64), n)
+
+		_, err = mustParseID(t, `-1`).Uint64()
+		assert.Error(t, err)
+		_, err = mustParseID(t, `18446744073709551616`).Uint64()
+		assert.Error(t, err)
+	})
+
+	t.Run("BigInt", func(t *testing.T) {
+		n, err := mustParseID(t, `123456789012345678901234567890`).BigInt()
+		require.NoError(t, err)
+		assert.Equal(t, "123456789012345678901234567890", n.String())
+
+		_, err = mustParseID(t, `0.5`).BigInt()
+		assert.Error(t, err)
+		_, err = NullID().BigInt()
+		assert.Error(t, err)
+	})
+
+	t.Run("Float64", func(t *testing.T) {
+		f, err := mustParseID(t, `3.25`).Float64()
+		require.NoError(t, err)
+		assert.Equal(t, 3.25, f)
+
+		_, err = StringID("3.25").Float64()
+		assert.Error(t, err)
+	})
+}
+
+func TestID_Value(t *testing.T) {
+	assert.Nil(t, ID{}.Value())
+	assert.Nil(t, NullID().Value())
+	assert.Equal(t, "abc", StringID("abc").Value())
+	assert.Equal(t, int64(7), Int64ID(7).Value())
+	assert.Equal(t, 1.5, Float64ID(1.5).Value())
+	assert.Equal(t, float64(1), mustParseID(t, `1.0`).Value())
+}
+
+func TestID_Equal(t *testing.T) {
+	assert.True(t, Int64ID(1).Equal(mustParseID(t, `1`)))
+	assert.True(t, StringID("a").Equal(mustParseID(t, `"a"`)))
+	assert.False(t, Int64ID(1).Equal(StringID("1")))
+	assert.False(t, Int64ID(1).Equal(mustParseID(t, `1.0`)), "raw encodings differ")
+	assert.False(t, ID{}.Equal(NullID()))
+
+	// IDs are comparable and usable as map keys
+	m := map[ID]int{Int64ID(1): 1, StringID("1"): 2}
+	assert.Equal(t, 1, m[mustParseID(t, `1`)])
+	assert.Equal(t, 2, m[mustParseID(t, `"1"`)])
+}
+
+func TestID_JSON(t *testing.T) {
+	t.Run("Marshal", func(t *testing.T) {
+		type wrapper struct {
+			ID ID `json:"id"`
+		}
+
+		data, err := json.Marshal(wrapper{ID: mustParseID(t, `1.0`)})
+		require.NoError(t, err)
+		assert.Equal(t, `{"id":1.0}`, string(data))
+
+		data, err = json.Marshal(wrapper{})
+		require.NoError(t, err)
+		assert.Equal(t, `{"id":null}`, string(data))
+
+		_, err = json.Marshal(wrapper{ID: toID(true)})
+		assert.Error(t, err)
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		var id ID
+		require.NoError(t, json.Unmarshal([]byte(`18446744073709551616`), &id))
+		assert.Equal(t, "18446744073709551616", id.String())
+
+		require.NoError(t, json.Unmarshal([]byte(`null`), &id))
+		assert.True(t, id.IsNull())
+
+		assert.Error(t, json.Unmarshal([]byte(`true`), &id))
+	})
+}
+
+func TestIsJSONNumber(t *testing.T) {
+	valid := []string{"0", "-0", "1", "10", "-12.5", "1e10", "1E+2", "1.5e-3", "0.0"}
+	for _, s := range valid {
+		assert.True(t, isJSONNumber(s), "%q should be valid", s)
+	}
+
+	invalid := []string{"", "-", "01", "1.", ".5", "+1", "1e", "1e+", "1x", "NaN", "0x1"}
+	for _, s := range invalid {
+		assert.False(t, isJSONNumber(s), "%q should be invalid", s)
+	}
+}
+
+// mustParseID parses a raw ID, failing the test on error.
+func mustParseID(t *testing.T, raw string) ID {
+	t.Helper()
+	id, err := ParseID([]byte(raw))
+	require.NoError(t, err)
+	return id
+}
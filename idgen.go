@@ -0,0 +1,210 @@
+package jsonrpc
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mrand "math/rand/v2"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// maxSafeInteger is the largest integer that round-trips exactly through an IEEE 754 double,
+	// which keeps generated IDs safe for JavaScript and other float64-based JSON decoders.
+	maxSafeInteger = 1<<53 - 1
+
+	// maxBatchIDAttempts bounds the number of regenerations when an ID collides within a batch.
+	maxBatchIDAttempts = 8
+
+	uuidLength = 16
+)
+
+// IDGenerator produces IDs for new requests. Implementations must be safe for concurrent use.
+type IDGenerator interface {
+	NextID() ID
+}
+
+// IDGeneratorFunc adapts an ordinary function to the IDGenerator interface.
+type IDGeneratorFunc func() ID
+
+// NextID calls f().
+func (f IDGeneratorFunc) NextID() ID {
+	return f()
+}
+
+var (
+	// idGenerator is the package-wide generator used by NewRequest and NewBatchRequest.
+	idGenerator IDGenerator = NewRandomIDGenerator()
+
+	// idGeneratorMutex protects idGenerator changes.
+	idGeneratorMutex sync.RWMutex
+)
+
+// SetIDGenerator configures the generator used for auto-generated IDs by NewRequest,
+// NewRequestWithRawParams and NewBatchRequest. Passing nil restores the default random generator.
+// This function is thread-safe and affects all subsequently created requests.
+//
+// Example usage:
+//
+//	jsonrpc.SetIDGenerator(jsonrpc.NewPrefixedIDGenerator("svc-"))
+func SetIDGenerator(gen IDGenerator) {
+	idGeneratorMutex.Lock()
+	defer idGeneratorMutex.Unlock()
+
+	if gen == nil {
+		gen = NewRandomIDGenerator()
+	}
+	idGenerator = gen
+}
+
+// GetIDGenerator returns the currently configured package-wide ID generator.
+func GetIDGenerator() IDGenerator {
+	idGeneratorMutex.RLock()
+	defer idGeneratorMutex.RUnlock()
+	return idGenerator
+}
+
+// nextID returns a new ID from the package-wide generator.
+func nextID() ID {
+	return GetIDGenerator().NextID()
+}
+
+// SequentialIDGenerator generates increasing integer IDs using an atomic counter.
+type SequentialIDGenerator struct {
+	counter atomic.Int64
+}
+
+// NewSequentialIDGenerator returns a generator whose first ID is start.
+func NewSequentialIDGenerator(start int64) *SequentialIDGenerator {
+	gen := &SequentialIDGenerator{}
+	gen.counter.Store(start - 1)
+	return gen
+}
+
+// NextID returns the next integer ID.
+func (g *SequentialIDGenerator) NextID() ID {
+	return Int64ID(g.counter.Add(1))
+}
+
+// PrefixedIDGenerator generates string IDs made of a fixed prefix and an increasing counter, e.g.
+// "svc-1", "svc-2". Prefixed IDs make it easy to correlate requests across services in logs.
+type PrefixedIDGenerator struct {
+	prefix  string
+	counter atomic.Uint64
+}
+
+// NewPrefixedIDGenerator returns a generator producing IDs of the form prefix + counter, starting
+// at 1.
+func NewPrefixedIDGenerator(prefix string) *PrefixedIDGenerator {
+	return &PrefixedIDGenerator{prefix: prefix}
+}
+
+// NextID returns the next prefixed string ID.
+func (g *PrefixedIDGenerator) NextID() ID {
+	return StringID(g.prefix + strconv.FormatUint(g.counter.Add(1), decimalBase))
+}
+
+// RandomIDGenerator generates random non-negative integer IDs. IDs are kept within the range of
+// integers exactly representable as float64, so clients decoding numbers as doubles see them
+// unchanged. Collisions within a batch are handled by NewBatchRequest.
+type RandomIDGenerator struct{}
+
+// NewRandomIDGenerator returns a random integer ID generator.
+func NewRandomIDGenerator() *RandomIDGenerator {
+	return &RandomIDGenerator{}
+}
+
+// NextID returns a random integer ID.
+func (*RandomIDGenerator) NextID() ID {
+	return Int64ID(mrand.Int64N(maxSafeInteger))
+}
+
+// UUIDv4Generator generates random RFC 9562 version 4 UUID strings.
+type UUIDv4Generator struct{}
+
+// NewUUIDv4Generator returns a UUIDv4 string ID generator.
+func NewUUIDv4Generator() *UUIDv4Generator {
+	return &UUIDv4Generator{}
+}
+
+// NextID returns a new UUIDv4 string ID.
+func (*UUIDv4Generator) NextID() ID {
+	var u [uuidLength]byte
+	_, _ = rand.Read(u[:])      // Never returns an error
+	u[6] = (u[6] & 0x0f) | 0x40 // Version 4
+	u[8] = (u[8] & 0x3f) | 0x80 // Variant 10
+	return StringID(formatUUID(u))
+}
+
+// UUIDv7Generator generates RFC 9562 version 7 UUID strings. Version 7 UUIDs start with a
+// millisecond timestamp, so IDs sort by creation time, which helps when correlating logs.
+type UUIDv7Generator struct {
+	now func() time.Time
+}
+
+// NewUUIDv7Generator returns a UUIDv7 string ID generator.
+func NewUUIDv7Generator() *UUIDv7Generator {
+	return &UUIDv7Generator{now: time.Now}
+}
+
+// NextID returns a new UUIDv7 string ID.
+func (g *UUIDv7Generator) NextID() ID {
+	var u [uuidLength]byte
+	_, _ = rand.Read(u[6:]) // Never returns an error
+
+	// The first 48 bits hold the Unix timestamp in milliseconds
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(g.now().UnixMilli()))
+	copy(u[:6], ts[2:])
+
+	u[6] = (u[6] & 0x0f) | 0x70 // Version 7
+	u[8] = (u[8] & 0x3f) | 0x80 // Variant 10
+	return StringID(formatUUID(u))
+}
+
+// formatUUID formats a UUID in its canonical 8-4-4-4-12 hex form.
+func formatUUID(u [uuidLength]byte) string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// uniqueBatchIDs returns n IDs from gen that are unique within the batch. Colliding IDs are
+// regenerated a bounded number of times before giving up.
+func uniqueBatchIDs(gen IDGenerator, n int) ([]ID, error) {
+	if gen == nil {
+		return nil, errors.New("id generator cannot be nil")
+	}
+
+	ids := make([]ID, n)
+	seen := make(map[ID]struct{}, n)
+	for i := range ids {
+		id := gen.NextID()
+		for attempt := 1; ; attempt++ {
+			if _, dup := seen[id]; !dup {
+				break
+			}
+			if attempt >= maxBatchIDAttempts {
+				return nil, fmt.Errorf("failed to generate unique id at index %d", i)
+			}
+			id = gen.NextID()
+		}
+		seen[id] = struct{}{}
+		ids[i] = id
+	}
+
+	return ids, nil
+}
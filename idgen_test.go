@@ -0,0 +1,171 @@
+package jsonrpc
+
+import (
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var uuidPattern = regexp.MustCompile(
+	`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+)
+
+func TestSequentialIDGenerator(t *testing.T) {
+	t.Run("Starts at the given value", func(t *testing.T) {
+		gen := NewSequentialIDGenerator(10)
+
+		assert.Equal(t, Int64ID(10), gen.NextID())
+		assert.Equal(t, Int64ID(11), gen.NextID())
+		assert.Equal(t, Int64ID(12), gen.NextID())
+	})
+
+	t.Run("Concurrent use yields unique IDs", func(t *testing.T) {
+		gen := NewSequentialIDGenerator(1)
+		const goroutines, perGoroutine = 8, 500
+
+		var mu sync.Mutex
+		seen := make(map[ID]struct{}, goroutines*perGoroutine)
+		var wg sync.WaitGroup
+		for range goroutines {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range perGoroutine {
+					id := gen.NextID()
+					mu.Lock()
+					seen[id] = struct{}{}
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Len(t, seen, goroutines*perGoroutine)
+	})
+}
+
+func TestPrefixedIDGenerator(t *testing.T) {
+	gen := NewPrefixedIDGenerator("svc-")
+
+	first := gen.NextID()
+	assert.True(t, first.IsString())
+	assert.Equal(t, "svc-1", first.String())
+	assert.Equal(t, "svc-2", gen.NextID().String())
+}
+
+func TestRandomIDGenerator(t *testing.T) {
+	gen := NewRandomIDGenerator()
+
+	for range 100 {
+		id := gen.NextID()
+		require.True(t, id.IsNumber())
+		v, err := id.Int64()
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, v, int64(0))
+		assert.LessOrEqual(t, v, int64(maxSafeInteger))
+	}
+}
+
+func TestUUIDGenerators(t *testing.T) {
+	t.Run("UUIDv4 format and version", func(t *testing.T) {
+		gen := NewUUIDv4Generator()
+
+		id := gen.NextID()
+		require.True(t, id.IsString())
+		assert.Regexp(t, uuidPattern, id.String())
+		assert.Equal(t, byte('4'), id.String()[14])
+		assert.NotEqual(t, id, gen.NextID())
+	})
+
+	t.Run("UUIDv7 format and version", func(t *testing.T) {
+		id := NewUUIDv7Generator().NextID()
+
+		require.True(t, id.IsString())
+		assert.Regexp(t, uuidPattern, id.String())
+		assert.Equal(t, byte('7'), id.String()[14])
+	})
+
+	t.Run("UUIDv7 sorts by timestamp", func(t *testing.T) {
+		now := time.UnixMilli(1_700_000_000_000)
+		gen := &UUIDv7Generator{now: func() time.Time { return now }}
+
+		earlier := gen.NextID().String()
+		now = now.Add(time.Millisecond)
+		later := gen.NextID().String()
+
+		assert.Less(t, earlier, later)
+		assert.Equal(t, "018bcfe5-6800", earlier[:13])
+	})
+}
+
+func TestSetIDGenerator(t *testing.T) {
+	t.Cleanup(func() { SetIDGenerator(nil) })
+
+	t.Run("NewRequest uses the configured generator", func(t *testing.T) {
+		SetIDGenerator(NewPrefixedIDGenerator("req-"))
+
+		assert.Equal(t, "req-1", NewRequest("ping", nil).IDString())
+		assert.Equal(t, "req-2", NewRequestWithRawParams("ping", []byte(`[]`)).IDString())
+	})
+
+	t.Run("IDGeneratorFunc", func(t *testing.T) {
+		SetIDGenerator(IDGeneratorFunc(func() ID { return StringID("fixed") }))
+
+		assert.Equal(t, StringID("fixed"), NewRequest("ping", nil).ID)
+	})
+
+	t.Run("Nil restores the default generator", func(t *testing.T) {
+		SetIDGenerator(nil)
+
+		assert.IsType(t, &RandomIDGenerator{}, GetIDGenerator())
+		assert.True(t, NewRequest("ping", nil).ID.IsNumber())
+	})
+}
+
+func TestNewBatchRequestWithGenerator(t *testing.T) {
+	t.Run("Uses the supplied generator", func(t *testing.T) {
+		reqs, err := NewBatchRequestWithGenerator(
+			NewSequentialIDGenerator(1), []string{"a", "b", "c"}, nil,
+		)
+		require.NoError(t, err)
+		require.Len(t, reqs, 3)
+
+		for i, req := range reqs {
+			assert.Equal(t, Int64ID(int64(i+1)), req.ID)
+		}
+	})
+
+	t.Run("Regenerates colliding IDs", func(t *testing.T) {
+		values := []int64{1, 1, 1, 2, 2, 3}
+		var next int
+		gen := IDGeneratorFunc(func() ID {
+			id := Int64ID(values[next])
+			next++
+			return id
+		})
+
+		reqs, err := NewBatchRequestWithGenerator(gen, []string{"a", "b", "c"}, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, Int64ID(1), reqs[0].ID)
+		assert.Equal(t, Int64ID(2), reqs[1].ID)
+		assert.Equal(t, Int64ID(3), reqs[2].ID)
+	})
+
+	t.Run("Fails when the generator keeps colliding", func(t *testing.T) {
+		gen := IDGeneratorFunc(func() ID { return Int64ID(1) })
+
+		_, err := NewBatchRequestWithGenerator(gen, []string{"a", "b"}, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unique id at index 1")
+	})
+
+	t.Run("Nil generator", func(t *testing.T) {
+		_, err := NewBatchRequestWithGenerator(nil, []string{"a"}, nil)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,575 @@
+package jsonrpc
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic/ast"
+)
+
+// Path is a compiled JSONPath-style expression selecting values within a JSON document. Paths are
+// compiled once, e.g. when loading configuration, and can then be evaluated repeatedly and
+// concurrently against Response results with Response.Query and its typed variants, or used to
+// select values in a RedactionPolicy.
+//
+// The supported syntax is a subset of JSONPath:
+//
+//	$                    the root; optional, "$.a" and "a" are equivalent
+//	.key or ['key']      an object member; the bracket form allows any key
+//	[n]                  an array element; negative indices count from the end
+//	.* or [*]            all elements of an array or all member values of an object
+//	[?(@.a.b op value)]  the elements or member values for which the comparison holds
+//	[?(@.a)]             the elements or member values for which the relative path exists
+//
+// Filter operators are ==, !=, <, <=, > and >=. Values are numbers, quoted strings, true, false
+// or null. Numbers are compared numerically and strings lexicographically; comparing values of
+// different types only satisfies !=.
+//
+// Example expressions:
+//
+//	transactions[*].hash
+//	logs[?(@.removed==false)]
+//	$.blocks[-1].number
+type Path struct {
+	expr     string
+	segments []pathSegment
+}
+
+// pathSegmentKind identifies the type of a path segment.
+type pathSegmentKind uint8
+
+const (
+	segmentKey pathSegmentKind = iota
+	segmentIndex
+	segmentWildcard
+	segmentFilter
+)
+
+// pathSegment is a single step of a compiled path.
+type pathSegment struct {
+	kind   pathSegmentKind
+	key    string
+	index  int
+	filter *pathFilter
+}
+
+// filterOp is a comparison operator of a filter expression.
+type filterOp uint8
+
+const (
+	filterExists filterOp = iota
+	filterEq
+	filterNe
+	filterLt
+	filterLe
+	filterGt
+	filterGe
+)
+
+// pathFilter is a compiled filter expression, comparing the value at a relative path to a literal.
+type pathFilter struct {
+	path  []pathSegment
+	op    filterOp
+	value filterValue
+}
+
+// filterValueKind identifies the type of a filter literal.
+type filterValueKind uint8
+
+const (
+	filterValueNull filterValueKind = iota
+	filterValueBool
+	filterValueNumber
+	filterValueString
+)
+
+// filterValue is a literal in a filter expression.
+type filterValue struct {
+	kind filterValueKind
+	b    bool
+	num  float64
+	str  string
+}
+
+// CompilePath parses a path expression. See Path for the supported syntax.
+func CompilePath(expr string) (*Path, error) {
+	p := &pathParser{expr: expr}
+	segments, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", expr, err)
+	}
+	return &Path{expr: expr, segments: segments}, nil
+}
+
+// MustCompilePath is like CompilePath but panics if the expression cannot be parsed. It simplifies
+// initialization of global variables holding compiled paths.
+func MustCompilePath(expr string) *Path {
+	p, err := CompilePath(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the source expression of the path.
+func (p *Path) String() string {
+	return p.expr
+}
+
+// IsSingular returns true if the path selects at most one value, i.e. it contains no wildcards
+// or filters.
+func (p *Path) IsSingular() bool {
+	for _, seg := range p.segments {
+		if seg.kind == segmentWildcard || seg.kind == segmentFilter {
+			return false
+		}
+	}
+	return true
+}
+
+// keyPathSegments converts a sequence of object keys and array indices, as accepted by the
+// ByPath accessors, to path segments.
+func keyPathSegments(path []any) ([]pathSegment, error) {
+	segments := make([]pathSegment, len(path))
+	for i, elem := range path {
+		switch v := elem.(type) {
+		case string:
+			segments[i] = pathSegment{kind: segmentKey, key: v}
+		case int:
+			segments[i] = pathSegment{kind: segmentIndex, index: v}
+		default:
+			return nil, fmt.Errorf("path element %d must be a string or int, got %T", i, elem)
+		}
+	}
+	return segments, nil
+}
+
+// eval returns the nodes selected by the path from root, in document order.
+func (p *Path) eval(root ast.Node) []ast.Node {
+	nodes := []ast.Node{root}
+	for _, seg := range p.segments {
+		next := make([]ast.Node, 0, len(nodes))
+		for i := range nodes {
+			next = seg.apply(&nodes[i], next)
+		}
+		if len(next) == 0 {
+			return nil
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// apply appends the nodes selected by the segment from node to dst.
+func (s *pathSegment) apply(node *ast.Node, dst []ast.Node) []ast.Node {
+	switch s.kind {
+	case segmentKey:
+		if node.TypeSafe() != ast.V_OBJECT {
+			return dst
+		}
+		if child := node.Get(s.key); child.Exists() {
+			dst = append(dst, *child)
+		}
+	case segmentIndex:
+		if child, ok := indexNode(node, s.index); ok {
+			dst = append(dst, child)
+		}
+	case segmentWildcard, segmentFilter:
+		switch node.TypeSafe() {
+		case ast.V_ARRAY, ast.V_OBJECT:
+		default:
+			return dst
+		}
+		_ = node.ForEach(func(_ ast.Sequence, child *ast.Node) bool {
+			if s.kind == segmentWildcard || s.filter.match(child) {
+				dst = append(dst, *child)
+			}
+			return true
+		})
+	}
+	return dst
+}
+
+// selects reports whether the segment selects the child at position seq of its parent. length is
+// the length of the parent if it is an array, which resolves negative indices.
+func (s *pathSegment) selects(seq ast.Sequence, length int, child *ast.Node) bool {
+	switch s.kind {
+	case segmentKey:
+		return seq.Key != nil && *seq.Key == s.key
+	case segmentIndex:
+		index := s.index
+		if index < 0 {
+			index += length
+		}
+		return seq.Key == nil && seq.Index == index
+	case segmentWildcard:
+		return true
+	case segmentFilter:
+		return s.filter.match(child)
+	default:
+		return false
+	}
+}
+
+// indexNode returns the array element at index, counting from the end for negative indices.
+func indexNode(node *ast.Node, index int) (ast.Node, bool) {
+	if node.TypeSafe() != ast.V_ARRAY {
+		return ast.Node{}, false
+	}
+	if index < 0 {
+		n, err := loadedLen(node)
+		if err != nil {
+			return ast.Node{}, false
+		}
+		index += n
+		if index < 0 {
+			return ast.Node{}, false
+		}
+	}
+
+	child := node.Index(index)
+	if !child.Exists() {
+		return ast.Node{}, false
+	}
+	return *child, true
+}
+
+// match reports whether the filter holds for node.
+func (f *pathFilter) match(node *ast.Node) bool {
+	target := *node
+	for i := range f.path {
+		selected := f.path[i].apply(&target, nil)
+		if len(selected) == 0 {
+			return false
+		}
+		target = selected[0]
+	}
+
+	if f.op == filterExists {
+		return true
+	}
+
+	cmp, ok := f.value.compare(&target)
+	if !ok {
+		// Values of different types are never equal and cannot be ordered
+		return f.op == filterNe
+	}
+
+	switch f.op {
+	case filterEq:
+		return cmp == 0
+	case filterNe:
+		return cmp != 0
+	case filterLt:
+		return cmp < 0
+	case filterLe:
+		return cmp <= 0
+	case filterGt:
+		return cmp > 0
+	case filterGe:
+		return cmp >= 0
+	default:
+		return false
+	}
+}
+
+// compare compares node to the literal, returning -1, 0 or 1 and whether the values are
+// comparable. Booleans and null only compare as equal or not equal.
+func (v *filterValue) compare(node *ast.Node) (int, bool) {
+	switch node.TypeSafe() {
+	case ast.V_NUMBER:
+		if v.kind != filterValueNumber {
+			return 0, false
+		}
+		raw, err := node.Raw()
+		if err != nil {
+			return 0, false
+		}
+		num, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return 0, false
+		}
+		switch {
+		case num < v.num:
+			return -1, true
+		case num > v.num:
+			return 1, true
+		default:
+			return 0, true
+		}
+	case ast.V_STRING:
+		if v.kind != filterValueString {
+			return 0, false
+		}
+		str, err := node.String()
+		if err != nil {
+			return 0, false
+		}
+		return strings.Compare(str, v.str), true
+	case ast.V_TRUE, ast.V_FALSE:
+		if v.kind != filterValueBool {
+			return 0, false
+		}
+		if (node.TypeSafe() == ast.V_TRUE) == v.b {
+			return 0, true
+		}
+		return 1, true
+	case ast.V_NULL:
+		if v.kind != filterValueNull {
+			return 0, false
+		}
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// pathParser is a recursive descent parser for path expressions.
+type pathParser struct {
+	expr string
+	pos  int
+}
+
+// parse parses the full expression into segments.
+func (p *pathParser) parse() ([]pathSegment, error) {
+	if p.expr == "" {
+		return nil, errors.New("empty expression")
+	}
+
+	if p.peek() == '$' {
+		p.pos++
+	} else if p.peek() != '.' && p.peek() != '[' {
+		// A leading key may omit the dot, e.g. "transactions[0]"
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		segments := []pathSegment{{kind: segmentKey, key: key}}
+		return p.parseSegments(segments, false)
+	}
+
+	return p.parseSegments(nil, false)
+}
+
+// parseSegments parses dotted and bracketed segments until the end of the expression, or, for
+// relative filter paths, until a character that cannot continue a path.
+func (p *pathParser) parseSegments(segments []pathSegment, relative bool) ([]pathSegment, error) {
+	for !p.done() {
+		switch p.peek() {
+		case '.':
+			p.pos++
+			if !relative && p.peek() == '*' {
+				p.pos++
+				segments = append(segments, pathSegment{kind: segmentWildcard})
+				continue
+			}
+			key, err := p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, pathSegment{kind: segmentKey, key: key})
+		case '[':
+			p.pos++
+			seg, err := p.parseBracket(relative)
+			if err != nil {
+				return nil, err
+			}
+			segments = append(segments, seg)
+		default:
+			if relative {
+				return segments, nil
+			}
+			return nil, fmt.Errorf("unexpected %q at offset %d", p.peek(), p.pos)
+		}
+	}
+	return segments, nil
+}
+
+// parseKey parses an unquoted member name.
+func (p *pathParser) parseKey() (string, error) {
+	start := p.pos
+	for !p.done() && !isPathDelimiter(p.peek()) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", fmt.Errorf("missing key at offset %d", start)
+	}
+	return p.expr[start:p.pos], nil
+}
+
+// parseBracket parses the contents of a bracketed segment, after the opening bracket.
+func (p *pathParser) parseBracket(relative bool) (pathSegment, error) {
+	var seg pathSegment
+
+	switch c := p.peek(); {
+	case c == '*' && !relative:
+		p.pos++
+		seg = pathSegment{kind: segmentWildcard}
+	case c == '?' && !relative:
+		filter, err := p.parseFilter()
+		if err != nil {
+			return seg, err
+		}
+		seg = pathSegment{kind: segmentFilter, filter: filter}
+	case c == '\'' || c == '"':
+		key, err := p.parseQuoted()
+		if err != nil {
+			return seg, err
+		}
+		seg = pathSegment{kind: segmentKey, key: key}
+	default:
+		start := p.pos
+		for !p.done() && p.peek() != ']' {
+			p.pos++
+		}
+		index, err := strconv.Atoi(strings.TrimSpace(p.expr[start:p.pos]))
+		if err != nil {
+			return seg, fmt.Errorf("invalid index at offset %d", start)
+		}
+		seg = pathSegment{kind: segmentIndex, index: index}
+	}
+
+	if p.peek() != ']' {
+		return seg, fmt.Errorf("expected ']' at offset %d", p.pos)
+	}
+	p.pos++
+	return seg, nil
+}
+
+// parseFilter parses a filter expression of the form ?(@path op value), starting at the '?'.
+func (p *pathParser) parseFilter() (*pathFilter, error) {
+	if !strings.HasPrefix(p.expr[p.pos:], "?(") {
+		return nil, fmt.Errorf("expected '?(' at offset %d", p.pos)
+	}
+	p.pos += 2
+	p.skipSpaces()
+
+	if p.peek() != '@' {
+		return nil, fmt.Errorf("expected '@' at offset %d", p.pos)
+	}
+	p.pos++
+
+	relPath, err := p.parseSegments(nil, true)
+	if err != nil {
+		return nil, err
+	}
+	filter := &pathFilter{path: relPath}
+
+	p.skipSpaces()
+	if p.peek() != ')' {
+		if filter.op, err = p.parseOp(); err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if filter.value, err = p.parseLiteral(); err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+	}
+
+	if p.peek() != ')' {
+		return nil, fmt.Errorf("expected ')' at offset %d", p.pos)
+	}
+	p.pos++
+	return filter, nil
+}
+
+// parseOp parses a comparison operator.
+func (p *pathParser) parseOp() (filterOp, error) {
+	ops := []struct {
+		token string
+		op    filterOp
+	}{
+		{"==", filterEq}, {"!=", filterNe}, {"<=", filterLe},
+		{">=", filterGe}, {"<", filterLt}, {">", filterGt},
+	}
+	for _, o := range ops {
+		if strings.HasPrefix(p.expr[p.pos:], o.token) {
+			p.pos += len(o.token)
+			return o.op, nil
+		}
+	}
+	return 0, fmt.Errorf("expected comparison operator at offset %d", p.pos)
+}
+
+// parseLiteral parses a filter value.
+func (p *pathParser) parseLiteral() (filterValue, error) {
+	if c := p.peek(); c == '\'' || c == '"' {
+		str, err := p.parseQuoted()
+		return filterValue{kind: filterValueString, str: str}, err
+	}
+
+	start := p.pos
+	for !p.done() && p.peek() != ')' && p.peek() != ' ' {
+		p.pos++
+	}
+	token := p.expr[start:p.pos]
+
+	switch token {
+	case "true", "false":
+		return filterValue{kind: filterValueBool, b: token == "true"}, nil
+	case "null":
+		return filterValue{kind: filterValueNull}, nil
+	}
+
+	num, err := strconv.ParseFloat(token, 64)
+	if err != nil {
+		return filterValue{}, fmt.Errorf("invalid value %q at offset %d", token, start)
+	}
+	return filterValue{kind: filterValueNumber, num: num}, nil
+}
+
+// parseQuoted parses a single or double quoted string. A backslash escapes the next character.
+func (p *pathParser) parseQuoted() (string, error) {
+	quote := p.peek()
+	start := p.pos
+	p.pos++
+
+	var sb strings.Builder
+	for !p.done() {
+		c := p.peek()
+		p.pos++
+		switch {
+		case c == '\\' && !p.done():
+			sb.WriteByte(p.peek())
+			p.pos++
+		case c == quote:
+			return sb.String(), nil
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated string at offset %d", start)
+}
+
+// skipSpaces advances past spaces.
+func (p *pathParser) skipSpaces() {
+	for !p.done() && p.peek() == ' ' {
+		p.pos++
+	}
+}
+
+// peek returns the current character, or 0 at the end of the expression.
+func (p *pathParser) peek() byte {
+	if p.done() {
+		return 0
+	}
+	return p.expr[p.pos]
+}
+
+// done returns true if the whole expression has been consumed.
+func (p *pathParser) done() bool {
+	return p.pos >= len(p.expr)
+}
+
+// isPathDelimiter returns true for characters that end an unquoted key.
+func isPathDelimiter(c byte) bool {
+	switch c {
+	case '.', '[', ']', ' ', '=', '!', '<', '>', ')':
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,80 @@
+package jsonrpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompilePath(t *testing.T) {
+	t.Run("Valid expressions", func(t *testing.T) {
+		cases := []struct {
+			expr     string
+			segments int
+			singular bool
+		}{
+			{expr: "$", segments: 0, singular: true},
+			{expr: "hash", segments: 1, singular: true},
+			{expr: "$.block.number", segments: 2, singular: true},
+			{expr: ".block.number", segments: 2, singular: true},
+			{expr: "transactions[0].hash", segments: 3, singular: true},
+			{expr: "blocks[-1]", segments: 2, singular: true},
+			{expr: "$['odd.key'][\"x\"]", segments: 2, singular: true},
+			{expr: "transactions[*].hash", segments: 3, singular: false},
+			{expr: "balances.*", segments: 2, singular: false},
+			{expr: "logs[?(@.removed==false)]", segments: 2, singular: false},
+			{expr: "logs[?(@.topics[0] == '0xabc')].data", segments: 3, singular: false},
+			{expr: "items[?(@.price >= 1.5)]", segments: 2, singular: false},
+			{expr: "items[?(@.meta)]", segments: 2, singular: false},
+			{expr: "items[?(@ != null)]", segments: 2, singular: false},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.expr, func(t *testing.T) {
+				p, err := CompilePath(tc.expr)
+				require.NoError(t, err)
+				assert.Len(t, p.segments, tc.segments)
+				assert.Equal(t, tc.singular, p.IsSingular())
+				assert.Equal(t, tc.expr, p.String())
+			})
+		}
+	})
+
+	t.Run("Filter literals", func(t *testing.T) {
+		p, err := CompilePath(`a[?(@.s=="it's")]`)
+		require.NoError(t, err)
+		filter := p.segments[1].filter
+		assert.Equal(t, filterEq, filter.op)
+		assert.Equal(t, filterValue{kind: filterValueString, str: "it's"}, filter.value)
+
+		p, err = CompilePath(`a[?(@.n < -2e3)]`)
+		require.NoError(t, err)
+		filter = p.segments[1].filter
+		assert.Equal(t, filterLt, filter.op)
+		assert.Equal(t, filterValue{kind: filterValueNumber, num: -2000}, filter.value)
+	})
+
+	t.Run("Invalid expressions", func(t *testing.T) {
+		for _, expr := range []string{
+			"",
+			"a..b",
+			"a[",
+			"a[x]",
+			"a['unterminated]",
+			"a[?(@.b = 1)]",
+			"a[?(@.b == bogus)]",
+			"a[?(b == 1)]",
+			"a[?(@.b == 1]",
+			"a]",
+		} {
+			_, err := CompilePath(expr)
+			assert.Error(t, err, expr)
+		}
+	})
+
+	t.Run("MustCompilePath panics on invalid expressions", func(t *testing.T) {
+		assert.Panics(t, func() { MustCompilePath("a[") })
+		assert.NotPanics(t, func() { MustCompilePath("a[0]") })
+	})
+}
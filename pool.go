@@ -0,0 +1,58 @@
+package jsonrpc
+
+import "sync"
+
+// requestPool and responsePool are sync.Pools for reusing Request and Response objects. Purpose is
+// to reduce allocations and GC pressure when handling very high message rates.
+var (
+	requestPool = sync.Pool{
+		New: func() any {
+			return &Request{}
+		},
+	}
+	responsePool = sync.Pool{
+		New: func() any {
+			return &Response{}
+		},
+	}
+)
+
+// AcquireRequest returns an empty Request from the pool. The Request can be populated with
+// DecodeRequestInto and should be returned with ReleaseRequest once it is no longer used.
+func AcquireRequest() *Request {
+	req, ok := requestPool.Get().(*Request)
+	if !ok {
+		return &Request{}
+	}
+	return req
+}
+
+// ReleaseRequest resets the Request and returns it to the pool. The Request, and any slices
+// obtained from it such as RawParams, must not be used after it has been released.
+func ReleaseRequest(req *Request) {
+	if req == nil {
+		return
+	}
+	req.Reset()
+	requestPool.Put(req)
+}
+
+// AcquireResponse returns an empty Response from the pool. The Response can be populated with
+// DecodeResponseInto and should be returned with ReleaseResponse once it is no longer used.
+func AcquireResponse() *Response {
+	resp, ok := responsePool.Get().(*Response)
+	if !ok {
+		return &Response{}
+	}
+	return resp
+}
+
+// ReleaseResponse resets the Response and returns it to the pool. The Response, and any slices
+// obtained from it such as RawResult, must not be used after it has been released.
+func ReleaseResponse(resp *Response) {
+	if resp == nil {
+		return
+	}
+	resp.Reset()
+	responsePool.Put(resp)
+}
@@ -0,0 +1,137 @@
+package jsonrpc
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireReleaseRequest(t *testing.T) {
+	t.Run("Acquired request is empty", func(t *testing.T) {
+		req := AcquireRequest()
+		require.NotNil(t, req)
+		assert.True(t, req.IsEmpty())
+		assert.True(t, req.ID.IsAbsent())
+		assert.Nil(t, req.Params())
+		ReleaseRequest(req)
+	})
+
+	t.Run("Decode into acquired request", func(t *testing.T) {
+		req := AcquireRequest()
+		defer ReleaseRequest(req)
+
+		err := DecodeRequestInto(req, []byte(`{"jsonrpc":"2.0","id":1,"method":"sum","params":[1,2]}`))
+		require.NoError(t, err)
+		assert.Equal(t, "sum", req.Method)
+		assert.Equal(t, Int64ID(1), req.ID)
+		assert.Equal(t, []any{float64(1), float64(2)}, req.Params())
+	})
+
+	t.Run("Release nil is a no-op", func(_ *testing.T) {
+		ReleaseRequest(nil)
+	})
+}
+
+func TestAcquireReleaseResponse(t *testing.T) {
+	t.Run("Acquired response is empty", func(t *testing.T) {
+		resp := AcquireResponse()
+		require.NotNil(t, resp)
+		assert.Empty(t, resp.Version())
+		assert.Nil(t, resp.IDOrNil())
+		assert.Nil(t, resp.RawResult())
+		ReleaseResponse(resp)
+	})
+
+	t.Run("Decode into acquired response", func(t *testing.T) {
+		resp := AcquireResponse()
+		defer ReleaseResponse(resp)
+
+		err := DecodeResponseInto(resp, []byte(`{"jsonrpc":"2.0","id":"a","result":{"n":1}}`))
+		require.NoError(t, err)
+		assert.Equal(t, "a", resp.IDString())
+		n, err := resp.PeekBytesByPath("n")
+		require.NoError(t, err)
+		assert.Equal(t, "1", string(n))
+	})
+
+	t.Run("Release nil is a no-op", func(_ *testing.T) {
+		ReleaseResponse(nil)
+	})
+
+	t.Run("Concurrent acquire and release", func(t *testing.T) {
+		data := []byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`)
+
+		var wg sync.WaitGroup
+		for range 8 {
+			wg.Go(func() {
+				for range 500 {
+					resp := AcquireResponse()
+					assert.NoError(t, DecodeResponseInto(resp, data))
+					assert.Equal(t, `"0x1"`, string(resp.RawResult()))
+					ReleaseResponse(resp)
+				}
+			})
+		}
+		wg.Wait()
+	})
+}
+
+func TestDecodeResponseInto(t *testing.T) {
+	t.Run("Reuse clears previous state", func(t *testing.T) {
+		resp := &Response{}
+		require.NoError(t, DecodeResponseInto(resp,
+			[]byte(`{"jsonrpc":"2.0","id":1,"result":{"a":"b"}}`)))
+		_, err := resp.PeekStringByPath("a")
+		require.NoError(t, err)
+
+		require.NoError(t, DecodeResponseInto(resp,
+			[]byte(`{"jsonrpc":"2.0","id":2,"error":{"code":-32601,"message":"not found"}}`)))
+		assert.Equal(t, "2", resp.IDString())
+		assert.Nil(t, resp.RawResult())
+		require.NotNil(t, resp.Err())
+		assert.Equal(t, MethodNotFound, resp.Err().Code)
+
+		_, err = resp.PeekStringByPath("a")
+		assert.Error(t, err, "AST cache should not survive reuse")
+	})
+
+	t.Run("Nil response", func(t *testing.T) {
+		err := DecodeResponseInto(nil, []byte(`{"jsonrpc":"2.0","id":1,"result":1}`))
+		assert.Error(t, err)
+	})
+
+	t.Run("Empty data", func(t *testing.T) {
+		assert.Error(t, DecodeResponseInto(&Response{}, nil))
+	})
+
+	t.Run("Invalid data", func(t *testing.T) {
+		assert.Error(t, DecodeResponseInto(&Response{}, []byte(`{"jsonrpc":"1.0","result":1}`)))
+	})
+}
+
+func TestDecodeRequestInto(t *testing.T) {
+	t.Run("Reuse clears previous state", func(t *testing.T) {
+		req := NewRequestWithID("old", map[string]any{"a": 1}, "x")
+		_, err := req.MarshalJSON()
+		require.NoError(t, err)
+
+		require.NoError(t, DecodeRequestInto(req, []byte(`{"jsonrpc":"2.0","method":"new"}`)))
+		assert.Equal(t, "new", req.Method)
+		assert.True(t, req.ID.IsAbsent())
+		assert.Nil(t, req.Params())
+
+		data, err := req.MarshalJSON()
+		require.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","method":"new"}`, string(data))
+	})
+
+	t.Run("Nil request", func(t *testing.T) {
+		assert.Error(t, DecodeRequestInto(nil, []byte(`{"jsonrpc":"2.0","method":"a"}`)))
+	})
+
+	t.Run("Empty data", func(t *testing.T) {
+		assert.Error(t, DecodeRequestInto(&Request{}, []byte(" ")))
+	})
+}
@@ -0,0 +1,286 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	// DefaultMaxRequestSize is the default limit on the size of incoming request bodies.
+	DefaultMaxRequestSize = 8 << 20 // 8 MiB
+
+	// msgUpstreamError is the error message of responses to requests that failed upstream.
+	msgUpstreamError = "Upstream request failed"
+)
+
+// ReverseProxyConfig configures a ReverseProxy.
+type ReverseProxyConfig struct {
+	// Upstream receives the forwarded requests. Required.
+	Upstream Upstream
+
+	// IDGenerator generates the IDs of forwarded requests. IDs are unique within each forwarded
+	// batch. Defaults to a sequential generator owned by the proxy.
+	IDGenerator IDGenerator
+
+	// MaxRequestSize limits the size of incoming request bodies in bytes. Defaults to
+	// DefaultMaxRequestSize.
+	MaxRequestSize int64
+
+	// ErrorHandler, if set, is called with upstream failures, e.g. for logging. Clients only
+	// receive a generic error response that does not disclose the failure.
+	ErrorHandler func(r *http.Request, err error)
+}
+
+// ReverseProxy is an http.Handler that forwards JSON-RPC requests to an Upstream.
+//
+// Incoming single and batch requests are decoded, and each request is forwarded with a new ID
+// that is unique within the forwarded batch, so that IDs of different clients cannot collide
+// upstream. Upstream responses are mapped back to the IDs of the client with Response.WithID,
+// passing results through as raw bytes without decoding them. Invalid requests are answered by
+// the proxy itself, and upstream failures are answered with error responses.
+//
+// Example usage:
+//
+//	upstream, err := jsonrpc.NewHTTPUpstream(jsonrpc.HTTPUpstreamConfig{URL: "http://node:8545"})
+//	proxy, err := jsonrpc.NewReverseProxy(jsonrpc.ReverseProxyConfig{Upstream: upstream})
+//	http.Handle("/", proxy)
+type ReverseProxy struct {
+	upstream       Upstream
+	ids            IDGenerator
+	maxRequestSize int64
+	errorHandler   func(*http.Request, error)
+}
+
+// NewReverseProxy creates a ReverseProxy with the given configuration.
+func NewReverseProxy(config ReverseProxyConfig) (*ReverseProxy, error) {
+	if config.Upstream == nil {
+		return nil, errors.New("upstream cannot be nil")
+	}
+
+	proxy := &ReverseProxy{
+		upstream:       config.Upstream,
+		ids:            config.IDGenerator,
+		maxRequestSize: config.MaxRequestSize,
+		errorHandler:   config.ErrorHandler,
+	}
+	if proxy.ids == nil {
+		proxy.ids = NewSequentialIDGenerator(1)
+	}
+	if proxy.maxRequestSize <= 0 {
+		proxy.maxRequestSize = DefaultMaxRequestSize
+	}
+
+	return proxy, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (p *ReverseProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, p.maxRequestSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge),
+				http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if !isBatchJSON(body) {
+		p.serveSingle(w, r, body)
+		return
+	}
+
+	elements, err := DecodeBatchRequestElements(body)
+	if err != nil {
+		writeProxyResponses(w, []*Response{NewDecodeErrorResponse(body, err)}, false)
+		return
+	}
+	writeProxyResponses(w, p.forwardBatch(r, elements), true)
+}
+
+// serveSingle forwards a single request.
+func (p *ReverseProxy) serveSingle(w http.ResponseWriter, r *http.Request, body []byte) {
+	req, err := DecodeRequest(body)
+	if err != nil {
+		writeProxyResponses(w, []*Response{NewDecodeErrorResponse(body, err)}, false)
+		return
+	}
+
+	forwarded := req
+	if !req.IsNotification() {
+		ids, err := uniqueBatchIDs(p.ids, 1)
+		if err == nil {
+			forwarded, err = req.WithID(ids[0])
+		}
+		if err != nil {
+			p.handleError(r, err)
+			writeProxyResponses(w, []*Response{upstreamErrorResponse(req.ID)}, false)
+			return
+		}
+	}
+
+	resp, err := p.upstream.Call(r.Context(), forwarded)
+	switch {
+	case req.IsNotification():
+		if err != nil {
+			p.handleError(r, err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case err != nil:
+		p.handleError(r, err)
+		resp = upstreamErrorResponse(req.ID)
+	case resp == nil:
+		p.handleError(r, errors.New("upstream returned no response"))
+		resp = upstreamErrorResponse(req.ID)
+	default:
+		if resp, err = resp.WithID(req.ID); err != nil {
+			p.handleError(r, err)
+			resp = upstreamErrorResponse(req.ID)
+		}
+	}
+
+	writeProxyResponses(w, []*Response{resp}, false)
+}
+
+// forwardBatch forwards the valid elements of a batch as a single batch, and returns the
+// responses to the batch in the order of its elements.
+func (p *ReverseProxy) forwardBatch(r *http.Request, elements []BatchRequestElement) []*Response {
+	calls := 0
+	for _, elem := range elements {
+		if elem.IsValid() && !elem.Request.IsNotification() {
+			calls++
+		}
+	}
+	ids, err := uniqueBatchIDs(p.ids, calls)
+	if err != nil {
+		p.handleError(r, err)
+		return batchErrorResponses(elements)
+	}
+
+	// Rewrite the IDs of the valid elements, recording the ID to respond with for each
+	forwarded := make([]*Request, 0, len(elements))
+	upstreamIDs := make([]ID, len(elements))
+	next := 0
+	for i, elem := range elements {
+		if !elem.IsValid() {
+			continue
+		}
+		req := elem.Request
+		if !req.IsNotification() {
+			upstreamIDs[i] = ids[next]
+			next++
+			if req, err = req.WithID(upstreamIDs[i]); err != nil {
+				p.handleError(r, err)
+				return batchErrorResponses(elements)
+			}
+		}
+		forwarded = append(forwarded, req)
+	}
+
+	var resps []*Response
+	if len(forwarded) > 0 {
+		if resps, err = p.upstream.CallBatch(r.Context(), forwarded); err != nil {
+			p.handleError(r, err)
+			return batchErrorResponses(elements)
+		}
+	}
+
+	byID := make(map[ID]*Response, len(resps))
+	for _, resp := range resps {
+		byID[resp.ID()] = resp
+	}
+
+	out := make([]*Response, 0, len(elements))
+	for i, elem := range elements {
+		switch {
+		case !elem.IsValid():
+			out = append(out, elem.ErrorResponse())
+		case elem.Request.IsNotification():
+			continue
+		default:
+			out = append(out, p.mapResponse(r, byID[upstreamIDs[i]], elem.ID))
+		}
+	}
+
+	return out
+}
+
+// mapResponse returns the upstream response with the ID of the client, or an error response if
+// there is no upstream response.
+func (p *ReverseProxy) mapResponse(r *http.Request, resp *Response, id ID) *Response {
+	if resp == nil {
+		p.handleError(r, fmt.Errorf("upstream returned no response for request with id %s", id))
+		return upstreamErrorResponse(id)
+	}
+
+	mapped, err := resp.WithID(id)
+	if err != nil {
+		p.handleError(r, err)
+		return upstreamErrorResponse(id)
+	}
+	return mapped
+}
+
+// handleError reports err to the configured ErrorHandler.
+func (p *ReverseProxy) handleError(r *http.Request, err error) {
+	if p.errorHandler != nil {
+		p.errorHandler(r, err)
+	}
+}
+
+// batchErrorResponses returns the responses to a batch whose forwarding failed: the error
+// responses of invalid elements and upstream error responses for all other requests.
+func batchErrorResponses(elements []BatchRequestElement) []*Response {
+	out := make([]*Response, 0, len(elements))
+	for _, elem := range elements {
+		switch {
+		case !elem.IsValid():
+			out = append(out, elem.ErrorResponse())
+		case !elem.Request.IsNotification():
+			out = append(out, upstreamErrorResponse(elem.ID))
+		}
+	}
+	return out
+}
+
+// upstreamErrorResponse returns the response to a request that failed upstream.
+func upstreamErrorResponse(id ID) *Response {
+	return NewErrorResponse(id, &Error{Code: ServerSideException, Message: msgUpstreamError})
+}
+
+// writeProxyResponses writes the responses as a single response or as a batch. Batches without
+// responses, i.e. of notifications only, are answered with an empty body.
+func writeProxyResponses(w http.ResponseWriter, resps []*Response, isBatch bool) {
+	if len(resps) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var buf bytes.Buffer
+	var err error
+	if isBatch {
+		_, err = WriteBatchResponse(&buf, resps)
+	} else {
+		_, err = resps[0].WriteTo(&buf)
+	}
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypeJSON)
+	_, _ = w.Write(buf.Bytes())
+}
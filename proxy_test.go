@@ -0,0 +1,200 @@
+package jsonrpc
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestProxy returns a ReverseProxy forwarding to an echo server, and the errors reported to
+// its ErrorHandler.
+func newTestProxy(t *testing.T) (*ReverseProxy, *[]error) {
+	t.Helper()
+
+	upstream, err := NewHTTPUpstream(HTTPUpstreamConfig{URL: newEchoServer(t).URL})
+	require.NoError(t, err)
+
+	var mutex sync.Mutex
+	var errs []error
+	proxy, err := NewReverseProxy(ReverseProxyConfig{
+		Upstream: upstream,
+		ErrorHandler: func(_ *http.Request, err error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			errs = append(errs, err)
+		},
+	})
+	require.NoError(t, err)
+
+	return proxy, &errs
+}
+
+// serveProxy posts body to handler and returns the recorded response.
+func serveProxy(handler http.Handler, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	return rec
+}
+
+func TestNewReverseProxy(t *testing.T) {
+	_, err := NewReverseProxy(ReverseProxyConfig{})
+	assert.Error(t, err)
+}
+
+func TestReverseProxy_Single(t *testing.T) {
+	proxy, errs := newTestProxy(t)
+
+	rec := serveProxy(proxy, `{"jsonrpc":"2.0","id":"client-1","method":"eth_blockNumber"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	resp := mustDecodeResponse(t, rec.Body.String())
+	assert.Equal(t, "client-1", resp.IDString())
+	upstreamID, err := resp.PeekStringByPath("upstreamId")
+	require.NoError(t, err)
+	assert.NotEqual(t, "client-1", upstreamID, "id should be rewritten upstream")
+	assert.Empty(t, *errs)
+
+	t.Run("Notification", func(t *testing.T) {
+		rec := serveProxy(proxy, `{"jsonrpc":"2.0","method":"notify"}`)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Empty(t, rec.Body.String())
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		rec := serveProxy(proxy, `{"jsonrpc":"2.0","id":3}`)
+		resp := mustDecodeResponse(t, rec.Body.String())
+		assert.Equal(t, "3", resp.IDString())
+		assert.Equal(t, InvalidRequest, resp.Err().Code)
+
+		rec = serveProxy(proxy, `{"jsonrpc":`)
+		resp = mustDecodeResponse(t, rec.Body.String())
+		assert.True(t, resp.ID().IsNull())
+		assert.Equal(t, ParseError, resp.Err().Code)
+	})
+}
+
+func TestReverseProxy_Batch(t *testing.T) {
+	proxy, errs := newTestProxy(t)
+
+	rec := serveProxy(proxy, `[
+		{"jsonrpc":"2.0","id":1,"method":"a"},
+		{"jsonrpc":"2.0","method":"notify"},
+		{"jsonrpc":"2.0","id":"x"},
+		{"jsonrpc":"2.0","id":1.0,"method":"b"}
+	]`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	resps, err := DecodeBatchResponse(rec.Body.Bytes())
+	require.NoError(t, err)
+	require.Len(t, resps, 3)
+
+	assert.Equal(t, "1", string(resps[0].ID().Raw()))
+	method, err := resps[0].PeekStringByPath("method")
+	require.NoError(t, err)
+	assert.Equal(t, "a", method)
+
+	assert.Equal(t, `"x"`, string(resps[1].ID().Raw()))
+	assert.Equal(t, InvalidRequest, resps[1].Err().Code)
+
+	assert.Equal(t, "1.0", string(resps[2].ID().Raw()), "colliding client ids should be kept apart")
+	method, err = resps[2].PeekStringByPath("method")
+	require.NoError(t, err)
+	assert.Equal(t, "b", method)
+	assert.Empty(t, *errs)
+
+	t.Run("Notifications only", func(t *testing.T) {
+		rec := serveProxy(proxy, `[{"jsonrpc":"2.0","method":"a"},{"jsonrpc":"2.0","method":"b"}]`)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("Invalid batch", func(t *testing.T) {
+		rec := serveProxy(proxy, `[]`)
+		resp := mustDecodeResponse(t, rec.Body.String())
+		assert.Equal(t, InvalidRequest, resp.Err().Code)
+	})
+}
+
+func TestReverseProxy_UpstreamFailure(t *testing.T) {
+	var reported []error
+	newProxy := func(upstream Upstream) *ReverseProxy {
+		proxy, err := NewReverseProxy(ReverseProxyConfig{
+			Upstream:     upstream,
+			ErrorHandler: func(_ *http.Request, err error) { reported = append(reported, err) },
+		})
+		require.NoError(t, err)
+		return proxy
+	}
+
+	t.Run("Transport error", func(t *testing.T) {
+		reported = nil
+		proxy := newProxy(&fakeUpstream{err: errors.New("connection refused")})
+
+		rec := serveProxy(proxy, `{"jsonrpc":"2.0","id":5,"method":"a"}`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		resp := mustDecodeResponse(t, rec.Body.String())
+		assert.Equal(t, "5", resp.IDString())
+		assert.Equal(t, ServerSideException, resp.Err().Code)
+		assert.NotContains(t, rec.Body.String(), "connection refused")
+
+		rec = serveProxy(proxy, `[{"jsonrpc":"2.0","id":1,"method":"a"},{"jsonrpc":"2.0","id":2}]`)
+		resps, err := DecodeBatchResponse(rec.Body.Bytes())
+		require.NoError(t, err)
+		require.Len(t, resps, 2)
+		assert.Equal(t, ServerSideException, resps[0].Err().Code)
+		assert.Equal(t, InvalidRequest, resps[1].Err().Code)
+
+		require.Len(t, reported, 2)
+		assert.EqualError(t, reported[0], "connection refused")
+	})
+
+	t.Run("Missing responses", func(t *testing.T) {
+		reported = nil
+		proxy := newProxy(&fakeUpstream{silent: true})
+
+		rec := serveProxy(proxy, `{"jsonrpc":"2.0","id":5,"method":"a"}`)
+		assert.Equal(t, ServerSideException, mustDecodeResponse(t, rec.Body.String()).Err().Code)
+
+		rec = serveProxy(proxy, `[{"jsonrpc":"2.0","id":1,"method":"a"}]`)
+		resps, err := DecodeBatchResponse(rec.Body.Bytes())
+		require.NoError(t, err)
+		require.Len(t, resps, 1)
+		assert.Equal(t, "1", resps[0].IDString())
+		assert.Equal(t, ServerSideException, resps[0].Err().Code)
+		assert.Len(t, reported, 2)
+	})
+
+	t.Run("Upstream error responses are passed through", func(t *testing.T) {
+		upstreamErr := NewErrorResponse(1, &Error{Code: MethodNotFound, Message: "Method not found"})
+		proxy := newProxy(&fakeUpstream{respond: func([]*Request) ([]*Response, error) {
+			return []*Response{upstreamErr}, nil
+		}})
+
+		rec := serveProxy(proxy, `{"jsonrpc":"2.0","id":"c","method":"a"}`)
+		resp := mustDecodeResponse(t, rec.Body.String())
+		assert.Equal(t, "c", resp.IDString())
+		assert.Equal(t, MethodNotFound, resp.Err().Code)
+	})
+}
+
+func TestReverseProxy_HTTP(t *testing.T) {
+	proxy, err := NewReverseProxy(ReverseProxyConfig{
+		Upstream:       &fakeUpstream{},
+		MaxRequestSize: 16,
+	})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+
+	rec = serveProxy(proxy, `{"jsonrpc":"2.0","id":1,"method":"too_long"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
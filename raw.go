@@ -0,0 +1,473 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"iter"
+	"unsafe"
+)
+
+// RawRequest is a read-only view of a JSON-RPC request that locates the members of the envelope
+// without decoding them. Parsing a RawRequest scans the data once and neither allocates nor copies:
+// all accessors return slices of, or strings sharing memory with, the parsed data. This makes it
+// suitable for proxies that only need the method and id to route a message.
+//
+// The view only checks the structure of the envelope. Use Decode to obtain a fully validated
+// *Request when needed. The data must not be modified while the view, or any value returned by
+// it, is in use.
+type RawRequest struct {
+	data    []byte
+	version []byte
+	method  []byte
+	id      []byte
+	params  []byte
+	batch   bool
+}
+
+// ParseRawRequest scans data into a RawRequest view. If data is a batch, the view only reports
+// IsBatch, and its elements can be visited with Elements.
+//
+// Example usage:
+//
+//	view, err := jsonrpc.ParseRawRequest(body)
+//	if err != nil {
+//		// Handle error
+//	}
+//	upstream := routes[view.Method()]
+func ParseRawRequest(data []byte) (RawRequest, error) {
+	v := RawRequest{data: data}
+
+	start, batch, err := rawEnvelopeStart(data)
+	if err != nil {
+		return RawRequest{}, err
+	}
+	if batch {
+		v.batch = true
+		return v, nil
+	}
+
+	err = scanRawObject(data, start, func(key, value []byte) error {
+		switch string(key) {
+		case "jsonrpc":
+			v.version = value
+		case "method":
+			if value[0] != '"' {
+				return errors.New("method field must be a string")
+			}
+			v.method = value
+		case "id":
+			v.id = value
+		case "params":
+			v.params = value
+		}
+		return nil
+	})
+	if err != nil {
+		return RawRequest{}, err
+	}
+
+	return v, nil
+}
+
+// IsBatch returns true if the data is a JSON array, i.e. a batch request.
+func (v RawRequest) IsBatch() bool {
+	return v.batch
+}
+
+// Method returns the method name. The returned string shares memory with the parsed data, unless
+// the method name contains escape sequences, in which case it is decoded into a new string.
+func (v RawRequest) Method() string {
+	return rawString(v.method)
+}
+
+// RawMethod returns the raw JSON encoding of the method member, including quotes, or nil if the
+// member is absent.
+func (v RawRequest) RawMethod() []byte {
+	return v.method
+}
+
+// RawID returns the raw JSON encoding of the id member, or nil if the member is absent.
+func (v RawRequest) RawID() []byte {
+	return v.id
+}
+
+// ID parses the id member into an ID.
+func (v RawRequest) ID() (ID, error) {
+	return ParseID(v.id)
+}
+
+// IsNotification returns true if the request has no id member.
+func (v RawRequest) IsNotification() bool {
+	return !v.batch && v.id == nil
+}
+
+// RawParams returns the raw JSON encoding of the params member, or nil if the member is absent.
+func (v RawRequest) RawParams() []byte {
+	return v.params
+}
+
+// Version returns the value of the jsonrpc member.
+func (v RawRequest) Version() string {
+	return rawString(v.version)
+}
+
+// Bytes returns the data the view was parsed from.
+func (v RawRequest) Bytes() []byte {
+	return v.data
+}
+
+// Decode fully decodes and validates the viewed data into a *Request. The returned Request does
+// not share memory with the view.
+func (v RawRequest) Decode() (*Request, error) {
+	if v.batch {
+		return nil, errors.New("cannot decode a batch into a single request")
+	}
+	return DecodeRequest(bytes.Clone(v.data))
+}
+
+// Elements returns an iterator over the elements of a batch view, yielding a RawRequest view of
+// each element. If an element cannot be parsed, the error is yielded and iteration stops.
+func (v RawRequest) Elements() iter.Seq2[RawRequest, error] {
+	return func(yield func(RawRequest, error) bool) {
+		if !v.batch {
+			yield(RawRequest{}, errors.New("view is not a batch"))
+			return
+		}
+		for elem, err := range rawBatchElements(v.data) {
+			if err != nil {
+				yield(RawRequest{}, err)
+				return
+			}
+			req, err := ParseRawRequest(elem)
+			if !yield(req, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// RawResponse is a read-only view of a JSON-RPC response that locates the members of the
+// envelope without decoding them. Like RawRequest, parsing neither allocates nor copies, and the
+// data must not be modified while the view is in use.
+type RawResponse struct {
+	data    []byte
+	version []byte
+	id      []byte
+	result  []byte
+	err     []byte
+	batch   bool
+}
+
+// ParseRawResponse scans data into a RawResponse view. If data is a batch, the view only reports
+// IsBatch, and its elements can be visited with Elements.
+func ParseRawResponse(data []byte) (RawResponse, error) {
+	v := RawResponse{data: data}
+
+	start, batch, err := rawEnvelopeStart(data)
+	if err != nil {
+		return RawResponse{}, err
+	}
+	if batch {
+		v.batch = true
+		return v, nil
+	}
+
+	err = scanRawObject(data, start, func(key, value []byte) error {
+		switch string(key) {
+		case "jsonrpc":
+			v.version = value
+		case "id":
+			v.id = value
+		case "result":
+			v.result = value
+		case "error":
+			v.err = value
+		}
+		return nil
+	})
+	if err != nil {
+		return RawResponse{}, err
+	}
+
+	return v, nil
+}
+
+// IsBatch returns true if the data is a JSON array, i.e. a batch response.
+func (v RawResponse) IsBatch() bool {
+	return v.batch
+}
+
+// RawID returns the raw JSON encoding of the id member, or nil if the member is absent.
+func (v RawResponse) RawID() []byte {
+	return v.id
+}
+
+// ID parses the id member into an ID.
+func (v RawResponse) ID() (ID, error) {
+	return ParseID(v.id)
+}
+
+// RawResult returns the raw JSON encoding of the result member, or nil if the member is absent.
+func (v RawResponse) RawResult() []byte {
+	return v.result
+}
+
+// RawError returns the raw JSON encoding of the error member, or nil if the member is absent.
+func (v RawResponse) RawError() []byte {
+	return v.err
+}
+
+// HasError returns true if the response has a non-null error member.
+func (v RawResponse) HasError() bool {
+	return v.err != nil && string(v.err) != "null"
+}
+
+// Version returns the value of the jsonrpc member.
+func (v RawResponse) Version() string {
+	return rawString(v.version)
+}
+
+// Bytes returns the data the view was parsed from.
+func (v RawResponse) Bytes() []byte {
+	return v.data
+}
+
+// Decode fully decodes and validates the viewed data into a *Response. The returned Response does
+// not share memory with the view.
+func (v RawResponse) Decode() (*Response, error) {
+	if v.batch {
+		return nil, errors.New("cannot decode a batch into a single response")
+	}
+	return DecodeResponse(bytes.Clone(v.data))
+}
+
+// Elements returns an iterator over the elements of a batch view, yielding a RawResponse view of
+// each element. If an element cannot be parsed, the error is yielded and iteration stops.
+func (v RawResponse) Elements() iter.Seq2[RawResponse, error] {
+	return func(yield func(RawResponse, error) bool) {
+		if !v.batch {
+			yield(RawResponse{}, errors.New("view is not a batch"))
+			return
+		}
+		for elem, err := range rawBatchElements(v.data) {
+			if err != nil {
+				yield(RawResponse{}, err)
+				return
+			}
+			resp, err := ParseRawResponse(elem)
+			if !yield(resp, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// rawEnvelopeStart returns the offset of the first non-whitespace byte of data, and whether data
+// is a batch. Returns an error if data is neither an object nor an array.
+func rawEnvelopeStart(data []byte) (int, bool, error) {
+	i := skipRawWhitespace(data, 0)
+	if i == len(data) {
+		return 0, false, errors.New(errEmptyData)
+	}
+
+	switch data[i] {
+	case '[':
+		return i, true, nil
+	case '{':
+		return i, false, nil
+	default:
+		return 0, false, fmt.Errorf("expected JSON object or array but found %q", data[i])
+	}
+}
+
+// scanRawObject calls fn with the raw key and value of each member of the object starting at
+// offset start. Keys are passed without quotes; escape sequences in keys are not decoded. Only
+// whitespace may follow the object.
+func scanRawObject(data []byte, start int, fn func(key, value []byte) error) error {
+	i := skipRawWhitespace(data, start+1)
+	if i < len(data) && data[i] == '}' {
+		return expectRawEnd(data, i+1)
+	}
+
+	for {
+		if i >= len(data) || data[i] != '"' {
+			return fmt.Errorf("expected object key at offset %d", i)
+		}
+		keyEnd, err := skipRawString(data, i)
+		if err != nil {
+			return err
+		}
+		key := data[i+1 : keyEnd-1]
+
+		i = skipRawWhitespace(data, keyEnd)
+		if i >= len(data) || data[i] != ':' {
+			return fmt.Errorf("expected ':' at offset %d", i)
+		}
+
+		valueStart := skipRawWhitespace(data, i+1)
+		valueEnd, err := skipRawValue(data, valueStart)
+		if err != nil {
+			return err
+		}
+		if err := fn(key, data[valueStart:valueEnd]); err != nil {
+			return err
+		}
+
+		i = skipRawWhitespace(data, valueEnd)
+		if i >= len(data) {
+			return fmt.Errorf("unexpected end of data at offset %d", i)
+		}
+		switch data[i] {
+		case ',':
+			i = skipRawWhitespace(data, i+1)
+		case '}':
+			return expectRawEnd(data, i+1)
+		default:
+			return fmt.Errorf("expected ',' or '}' at offset %d", i)
+		}
+	}
+}
+
+// rawBatchElements returns an iterator over the raw elements of the JSON array in data.
+func rawBatchElements(data []byte) iter.Seq2[[]byte, error] {
+	return func(yield func([]byte, error) bool) {
+		i := skipRawWhitespace(data, 0)
+		if i >= len(data) || data[i] != '[' {
+			yield(nil, errors.New("expected JSON array"))
+			return
+		}
+
+		i = skipRawWhitespace(data, i+1)
+		if i < len(data) && data[i] == ']' {
+			yield(nil, errors.New("batch must contain at least one element"))
+			return
+		}
+
+		for {
+			end, err := skipRawValue(data, i)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(data[i:end], nil) {
+				return
+			}
+
+			i = skipRawWhitespace(data, end)
+			if i >= len(data) {
+				yield(nil, fmt.Errorf("unexpected end of data at offset %d", i))
+				return
+			}
+			switch data[i] {
+			case ',':
+				i = skipRawWhitespace(data, i+1)
+			case ']':
+				if err := expectRawEnd(data, i+1); err != nil {
+					yield(nil, err)
+				}
+				return
+			default:
+				yield(nil, fmt.Errorf("expected ',' or ']' at offset %d", i))
+				return
+			}
+		}
+	}
+}
+
+// skipRawValue returns the offset just past the JSON value starting at offset i. Containers are
+// skipped by tracking nesting and strings; their contents are not validated.
+func skipRawValue(data []byte, i int) (int, error) {
+	if i >= len(data) {
+		return 0, fmt.Errorf("unexpected end of data at offset %d", i)
+	}
+
+	switch data[i] {
+	case '"':
+		return skipRawString(data, i)
+	case '{', '[':
+		depth := 0
+		for ; i < len(data); i++ {
+			switch data[i] {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return i + 1, nil
+				}
+			case '"':
+				end, err := skipRawString(data, i)
+				if err != nil {
+					return 0, err
+				}
+				i = end - 1
+			}
+		}
+		return 0, fmt.Errorf("unexpected end of data at offset %d", i)
+	case ',', ':', '}', ']':
+		return 0, fmt.Errorf("unexpected %q at offset %d", data[i], i)
+	}
+
+	// Literal: number, true, false or null
+	start := i
+	for i < len(data) && !isJSONWhitespace(data[i]) &&
+		data[i] != ',' && data[i] != '}' && data[i] != ']' {
+		i++
+	}
+	if i == start {
+		return 0, fmt.Errorf("expected value at offset %d", start)
+	}
+	return i, nil
+}
+
+// skipRawString returns the offset just past the string starting with the quote at offset i.
+func skipRawString(data []byte, i int) (int, error) {
+	for j := i + 1; j < len(data); j++ {
+		switch data[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated string at offset %d", i)
+}
+
+// skipRawWhitespace returns the offset of the first non-whitespace byte at or after offset i.
+func skipRawWhitespace(data []byte, i int) int {
+	for i < len(data) && isJSONWhitespace(data[i]) {
+		i++
+	}
+	return i
+}
+
+// expectRawEnd returns an error if anything but whitespace follows offset i.
+func expectRawEnd(data []byte, i int) error {
+	if i = skipRawWhitespace(data, i); i < len(data) {
+		return fmt.Errorf("unexpected data after end of value at offset %d", i)
+	}
+	return nil
+}
+
+// rawString returns the value of a raw JSON string without copying if it contains no escape
+// sequences. Returns an empty string if raw is not a string.
+func rawString(raw []byte) string {
+	if len(raw) < 2 || raw[0] != '"' {
+		return ""
+	}
+
+	inner := raw[1 : len(raw)-1]
+	if bytes.IndexByte(inner, '\\') < 0 {
+		if len(inner) == 0 {
+			return ""
+		}
+		return unsafe.String(&inner[0], len(inner))
+	}
+
+	var s string
+	if err := getSonicAPI().Unmarshal(raw, &s); err != nil {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,214 @@
+package jsonrpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRawRequest(t *testing.T) {
+	t.Run("Envelope members", func(t *testing.T) {
+		data := []byte(` {"jsonrpc":"2.0", "id": 18446744073709551616, "method":"eth_call",
+			"params":[{"to":"0x1","data":"}]\""},"latest"]} `)
+
+		view, err := ParseRawRequest(data)
+		require.NoError(t, err)
+
+		assert.False(t, view.IsBatch())
+		assert.False(t, view.IsNotification())
+		assert.Equal(t, "eth_call", view.Method())
+		assert.Equal(t, `"eth_call"`, string(view.RawMethod()))
+		assert.Equal(t, "2.0", view.Version())
+		assert.Equal(t, `18446744073709551616`, string(view.RawID()))
+		assert.Equal(t, `[{"to":"0x1","data":"}]\""},"latest"]`, string(view.RawParams()))
+		assert.Equal(t, data, view.Bytes())
+
+		id, err := view.ID()
+		require.NoError(t, err)
+		assert.Equal(t, "18446744073709551616", id.String())
+	})
+
+	t.Run("Notification without params", func(t *testing.T) {
+		view, err := ParseRawRequest([]byte(`{"jsonrpc":"2.0","method":"ping"}`))
+		require.NoError(t, err)
+
+		assert.True(t, view.IsNotification())
+		assert.Nil(t, view.RawParams())
+		id, err := view.ID()
+		require.NoError(t, err)
+		assert.True(t, id.IsAbsent())
+	})
+
+	t.Run("Escaped method", func(t *testing.T) {
+		view, err := ParseRawRequest([]byte(`{"jsonrpc":"2.0","method":"a\/b!","id":1}`))
+		require.NoError(t, err)
+		assert.Equal(t, "a/b!", view.Method())
+	})
+
+	t.Run("Shares memory with the data", func(t *testing.T) {
+		data := []byte(`{"jsonrpc":"2.0","method":"m","id":1,"params":[1]}`)
+		view, err := ParseRawRequest(data)
+		require.NoError(t, err)
+
+		data[len(data)-3] = '2'
+		assert.Equal(t, `[2]`, string(view.RawParams()))
+	})
+
+	t.Run("Does not allocate", func(t *testing.T) {
+		data := []byte(`{"jsonrpc":"2.0","id":"abc","method":"eth_getBalance",` +
+			`"params":["0x407d73d8a49eeb85d32cf465507dd71d507100c1","latest"]}`)
+
+		var method string
+		allocs := testing.AllocsPerRun(100, func() {
+			view, err := ParseRawRequest(data)
+			if err != nil {
+				panic(err)
+			}
+			method = view.Method()
+		})
+		assert.Zero(t, allocs)
+		assert.Equal(t, "eth_getBalance", method)
+	})
+
+	t.Run("Decode upgrades to a full request", func(t *testing.T) {
+		data := []byte(`{"jsonrpc":"2.0","id":7,"method":"sum","params":[1,2]}`)
+		view, err := ParseRawRequest(data)
+		require.NoError(t, err)
+
+		req, err := view.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, "sum", req.Method)
+		assert.Equal(t, Int64ID(7), req.ID)
+
+		// The decoded request does not share memory with the view
+		data[len(data)-3] = '9'
+		assert.JSONEq(t, `[1,2]`, string(req.RawParams()))
+	})
+
+	t.Run("Decode validates", func(t *testing.T) {
+		view, err := ParseRawRequest([]byte(`{"jsonrpc":"1.0","id":1,"method":"m"}`))
+		require.NoError(t, err)
+
+		_, err = view.Decode()
+		assert.Error(t, err)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		view, err := ParseRawRequest([]byte(` [{"jsonrpc":"2.0","id":1,"method":"a"},
+			{"jsonrpc":"2.0","method":"b"}] `))
+		require.NoError(t, err)
+		assert.True(t, view.IsBatch())
+		assert.False(t, view.IsNotification())
+
+		var methods []string
+		for elem, err := range view.Elements() {
+			require.NoError(t, err)
+			methods = append(methods, elem.Method())
+		}
+		assert.Equal(t, []string{"a", "b"}, methods)
+
+		_, err = view.Decode()
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid data", func(t *testing.T) {
+		for _, data := range []string{
+			``,
+			`  `,
+			`"str"`,
+			`{"method":1}`,
+			`{"method":"m"`,
+			`{"method" "m"}`,
+			`{"method":"m",}`,
+			`{"method":"m"} x`,
+			`{"params":[1,2}`,
+			`{"method":"unterminated}`,
+			`{method:"m"}`,
+		} {
+			_, err := ParseRawRequest([]byte(data))
+			assert.Error(t, err, data)
+		}
+	})
+
+	t.Run("Invalid batch elements", func(t *testing.T) {
+		for _, data := range []string{`[]`, `[{"method":"a"} {"method":"b"}]`, `[1,`, `[{}] x`} {
+			view, err := ParseRawRequest([]byte(data))
+			require.NoError(t, err, data)
+
+			var lastErr error
+			for _, err := range view.Elements() {
+				lastErr = err
+			}
+			assert.Error(t, lastErr, data)
+		}
+
+		view, err := ParseRawRequest([]byte(`{}`))
+		require.NoError(t, err)
+		for _, err := range view.Elements() {
+			assert.Error(t, err)
+		}
+	})
+}
+
+func TestParseRawResponse(t *testing.T) {
+	t.Run("Result", func(t *testing.T) {
+		view, err := ParseRawResponse([]byte(`{"jsonrpc":"2.0","id":"x","result":{"a":[1,2]}}`))
+		require.NoError(t, err)
+
+		assert.Equal(t, `"x"`, string(view.RawID()))
+		assert.Equal(t, `{"a":[1,2]}`, string(view.RawResult()))
+		assert.Nil(t, view.RawError())
+		assert.False(t, view.HasError())
+		assert.Equal(t, "2.0", view.Version())
+
+		resp, err := view.Decode()
+		require.NoError(t, err)
+		assert.Equal(t, StringID("x"), resp.ID())
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		view, err := ParseRawResponse(
+			[]byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"bad"}}`),
+		)
+		require.NoError(t, err)
+
+		assert.True(t, view.HasError())
+		assert.Equal(t, `{"code":-32600,"message":"bad"}`, string(view.RawError()))
+		id, err := view.ID()
+		require.NoError(t, err)
+		assert.True(t, id.IsNull())
+	})
+
+	t.Run("Does not allocate", func(t *testing.T) {
+		data := []byte(`{"jsonrpc":"2.0","id":1,"result":{"blocks":[1,2,3],"hash":"0xabc"}}`)
+
+		allocs := testing.AllocsPerRun(100, func() {
+			if _, err := ParseRawResponse(data); err != nil {
+				panic(err)
+			}
+		})
+		assert.Zero(t, allocs)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		view, err := ParseRawResponse([]byte(`[{"jsonrpc":"2.0","id":1,"result":1},` +
+			`{"jsonrpc":"2.0","id":2,"error":{"code":1,"message":"m"}}]`))
+		require.NoError(t, err)
+		require.True(t, view.IsBatch())
+
+		var errs []bool
+		for elem, err := range view.Elements() {
+			require.NoError(t, err)
+			errs = append(errs, elem.HasError())
+		}
+		assert.Equal(t, []bool{false, true}, errs)
+	})
+
+	t.Run("Invalid data", func(t *testing.T) {
+		_, err := ParseRawResponse([]byte(`{"id":1,"result":}`))
+		assert.Error(t, err)
+		_, err = ParseRawResponse(nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,295 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+
+	"github.com/bytedance/sonic/ast"
+)
+
+const (
+	// DefaultRedactionMask is the value that masked fields are replaced with when no MaskValue is
+	// configured.
+	DefaultRedactionMask = "[REDACTED]"
+
+	// redactionHashPrefix prefixes hashed values to make them recognizable in logs.
+	redactionHashPrefix = "sha256:"
+)
+
+// redactAction is the action applied to a value selected by a redaction rule. Higher values take
+// precedence when several rules select the same value.
+type redactAction uint8
+
+const (
+	redactNone redactAction = iota
+	redactMask
+	redactHash
+	redactRemove
+)
+
+// RedactionPolicy describes how to derive a copy of a result or params that is safe to log. Values
+// selected by the Mask paths are replaced with MaskValue, values selected by the Hash paths are
+// replaced with a SHA-256 digest of their JSON encoding, and values selected by the Remove paths
+// are dropped. If several rules select the same value, removing takes precedence over hashing,
+// which takes precedence over masking.
+//
+// If Keep is non-empty, the copy is first projected to only the values selected by the Keep paths,
+// keeping the structure of their ancestors, and the other rules are applied to the projection.
+// Array elements that are not kept are dropped, so kept elements may shift to lower indices.
+//
+// The policy is not modified by use and can be shared across goroutines.
+//
+// Example usage:
+//
+//	policy := &jsonrpc.RedactionPolicy{
+//		Mask: []*jsonrpc.Path{jsonrpc.MustCompilePath("user.email")},
+//		Hash: []*jsonrpc.Path{jsonrpc.MustCompilePath("user.id")},
+//	}
+//	safe, err := resp.Redact(policy)
+type RedactionPolicy struct {
+	Mask   []*Path
+	Hash   []*Path
+	Remove []*Path
+	Keep   []*Path
+
+	// MaskValue replaces masked values. Defaults to DefaultRedactionMask.
+	MaskValue string
+
+	// HashKey, if set, makes hashed values an HMAC-SHA256 with this key instead of a plain
+	// SHA-256, which prevents recovering low-entropy values such as phone numbers by brute force.
+	HashKey []byte
+}
+
+// Redact returns a copy of the response with the result redacted according to policy. The copy is
+// created with Clone, so the original response is untouched. Only the parts of the result on the
+// paths of the policy are rebuilt; everything else is copied as raw JSON without unmarshaling.
+// Responses without a result, e.g. error responses, are cloned unchanged.
+func (r *Response) Redact(policy *RedactionPolicy) (*Response, error) {
+	if policy == nil {
+		return nil, errors.New("redaction policy cannot be nil")
+	}
+
+	clone, err := r.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone response: %w", err)
+	}
+	if len(clone.result) == 0 {
+		return clone, nil
+	}
+
+	result, err := policy.apply(clone.result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redact result: %w", err)
+	}
+	clone.result = result
+
+	return clone, nil
+}
+
+// RedactParams returns a copy of the request with the params redacted according to policy. The
+// original request is untouched. Requests without params are copied unchanged, and if nothing
+// remains of the params after redaction, e.g. as no Keep path matches, the copy has no params, as
+// JSON-RPC 2.0 does not allow null params.
+func (r *Request) RedactParams(policy *RedactionPolicy) (*Request, error) {
+	if policy == nil {
+		return nil, errors.New("redaction policy cannot be nil")
+	}
+
+	params, err := r.getParamsBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	redacted := &Request{
+		JSONRPC: r.JSONRPC,
+		ID:      r.ID,
+		Method:  r.Method,
+	}
+	if isNullParams(params) {
+		return redacted, nil
+	}
+
+	result, err := policy.apply(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to redact params: %w", err)
+	}
+	if !isNullParams(result) {
+		redacted.rawParams = result
+	}
+
+	return redacted, nil
+}
+
+// isNullParams returns true if params are empty or JSON null, i.e. the request has no params.
+func isNullParams(params []byte) bool {
+	trimmed := bytes.TrimSpace(params)
+	return len(trimmed) == 0 || string(trimmed) == "null"
+}
+
+// redactRule is a path being matched against the document, with the segments that remain to be
+// matched below the current node.
+type redactRule struct {
+	segments []pathSegment
+	action   redactAction
+}
+
+// apply returns the redacted encoding of data.
+func (p *RedactionPolicy) apply(data []byte) ([]byte, error) {
+	root := ast.NewRaw(string(data))
+	if err := root.Check(); err != nil {
+		return nil, err
+	}
+
+	if len(p.Keep) > 0 {
+		rules := make([]redactRule, 0, len(p.Keep))
+		for _, path := range p.Keep {
+			rules = append(rules, redactRule{segments: path.segments})
+		}
+		projected, ok := projectNode(&root, rules)
+		if !ok {
+			return []byte("null"), nil
+		}
+		root = projected
+	}
+
+	rules := make([]redactRule, 0, len(p.Mask)+len(p.Hash)+len(p.Remove))
+	for _, group := range []struct {
+		paths  []*Path
+		action redactAction
+	}{{p.Mask, redactMask}, {p.Hash, redactHash}, {p.Remove, redactRemove}} {
+		for _, path := range group.paths {
+			rules = append(rules, redactRule{segments: path.segments, action: group.action})
+		}
+	}
+
+	redacted, ok := p.redactNode(&root, rules)
+	if !ok {
+		// The root itself was removed
+		return []byte("null"), nil
+	}
+
+	return redacted.MarshalJSON()
+}
+
+// redactNode returns node with the rules applied, and false if the node is removed. Subtrees not
+// on the path of any rule are returned as-is.
+func (p *RedactionPolicy) redactNode(node *ast.Node, rules []redactRule) (ast.Node, bool) {
+	if len(rules) == 0 {
+		return *node, true
+	}
+
+	action := redactNone
+	for _, rule := range rules {
+		if len(rule.segments) == 0 && rule.action > action {
+			action = rule.action
+		}
+	}
+
+	switch action {
+	case redactRemove:
+		return ast.Node{}, false
+	case redactHash:
+		return ast.NewString(p.hashValue(node)), true
+	case redactMask:
+		mask := p.MaskValue
+		if mask == "" {
+			mask = DefaultRedactionMask
+		}
+		return ast.NewString(mask), true
+	}
+
+	return rebuildNode(node, rules, p.redactNode, true)
+}
+
+// hashValue returns the digest of the JSON encoding of node.
+func (p *RedactionPolicy) hashValue(node *ast.Node) string {
+	raw, _ := node.Raw() // The document was checked before redaction
+
+	var h hash.Hash
+	if len(p.HashKey) > 0 {
+		h = hmac.New(sha256.New, p.HashKey)
+	} else {
+		h = sha256.New()
+	}
+	h.Write([]byte(raw))
+
+	return redactionHashPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// projectNode returns node reduced to the values selected by the rules, and false if nothing is
+// selected.
+func projectNode(node *ast.Node, rules []redactRule) (ast.Node, bool) {
+	for _, rule := range rules {
+		if len(rule.segments) == 0 {
+			return *node, true
+		}
+	}
+	return rebuildNode(node, rules, projectNode, false)
+}
+
+// rebuildNode rebuilds the array or object node, passing each child to transform with the rules
+// whose next segment selects it. Children selected by no rule are kept if keepUnmatched is true,
+// and dropped otherwise. Returns false if the node is not a container, or if no child is kept and
+// keepUnmatched is false.
+func rebuildNode(
+	node *ast.Node,
+	rules []redactRule,
+	transform func(*ast.Node, []redactRule) (ast.Node, bool),
+	keepUnmatched bool,
+) (ast.Node, bool) {
+	typ := node.TypeSafe()
+	if typ != ast.V_ARRAY && typ != ast.V_OBJECT {
+		return *node, keepUnmatched
+	}
+
+	length := 0
+	if typ == ast.V_ARRAY {
+		length, _ = loadedLen(node)
+	}
+
+	var elems []ast.Node
+	var pairs []ast.Pair
+	var childRules []redactRule
+	_ = node.ForEach(func(seq ast.Sequence, child *ast.Node) bool {
+		childRules = childRules[:0]
+		for _, rule := range rules {
+			if len(rule.segments) > 0 && rule.segments[0].selects(seq, length, child) {
+				childRules = append(childRules, redactRule{
+					segments: rule.segments[1:],
+					action:   rule.action,
+				})
+			}
+		}
+
+		var out ast.Node
+		kept := keepUnmatched
+		if len(childRules) > 0 {
+			out, kept = transform(child, childRules)
+		} else {
+			out = *child
+		}
+		if !kept {
+			return true
+		}
+
+		if seq.Key != nil {
+			pairs = append(pairs, ast.NewPair(*seq.Key, out))
+		} else {
+			elems = append(elems, out)
+		}
+		return true
+	})
+
+	if !keepUnmatched && len(elems) == 0 && len(pairs) == 0 {
+		return ast.Node{}, false
+	}
+	if typ == ast.V_ARRAY {
+		return ast.NewArray(elems), true
+	}
+	return ast.NewObject(pairs), true
+}
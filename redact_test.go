@@ -0,0 +1,207 @@
+package jsonrpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const redactTestResponse = `{"jsonrpc":"2.0","id":1,"result":{
+	"user":{"email":"alice@example.com","id":42,"name":"Alice"},
+	"cards":["4111","5500","3400"],
+	"txs":[{"to":"bob","amount":5},{"to":"carol","amount":50}]
+}}`
+
+func paths(exprs ...string) []*Path {
+	out := make([]*Path, len(exprs))
+	for i, expr := range exprs {
+		out[i] = MustCompilePath(expr)
+	}
+	return out
+}
+
+func TestResponse_Redact(t *testing.T) {
+	t.Run("Mask, hash and remove", func(t *testing.T) {
+		resp := mustDecodeResponse(t, redactTestResponse)
+		original := string(resp.RawResult())
+
+		redacted, err := resp.Redact(&RedactionPolicy{
+			Mask:   paths("user.email", "txs[*].to"),
+			Hash:   paths("user.id"),
+			Remove: paths("cards[-1]"),
+		})
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{
+			"user":{
+				"email":"[REDACTED]",
+				"id":"sha256:73475cb40a568e8da8a045ced110137e159f890ac4da883b6b17dc651b3a8049",
+				"name":"Alice"
+			},
+			"cards":["4111","5500"],
+			"txs":[{"to":"[REDACTED]","amount":5},{"to":"[REDACTED]","amount":50}]
+		}`, string(redacted.RawResult()))
+		assert.Equal(t, resp.ID(), redacted.ID())
+
+		// The original response is untouched
+		assert.Equal(t, original, string(resp.RawResult()))
+		email, err := resp.PeekStringByPath("user", "email")
+		require.NoError(t, err)
+		assert.Equal(t, "alice@example.com", email)
+	})
+
+	t.Run("Negative indices into lazily parsed arrays", func(t *testing.T) {
+		resp := mustDecodeResponse(t, lazyArrayResponse)
+
+		redacted, err := resp.Redact(&RedactionPolicy{
+			Mask:   paths("b.c[-1]"),
+			Remove: paths("a[-1]"),
+		})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"a":[1,2,3,4],"b":{"c":[6,"[REDACTED]"]}}`,
+			string(redacted.RawResult()))
+	})
+
+	t.Run("Precedence and custom mask", func(t *testing.T) {
+		resp := mustDecodeResponse(t, redactTestResponse)
+
+		redacted, err := resp.Redact(&RedactionPolicy{
+			Mask:      paths("user.*", "txs[?(@.amount > 10)].to"),
+			Remove:    paths("user.name"),
+			MaskValue: "***",
+		})
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{
+			"user":{"email":"***","id":"***"},
+			"cards":["4111","5500","3400"],
+			"txs":[{"to":"bob","amount":5},{"to":"***","amount":50}]
+		}`, string(redacted.RawResult()))
+	})
+
+	t.Run("Keyed hashing", func(t *testing.T) {
+		resp := mustDecodeResponse(t, redactTestResponse)
+
+		plain, err := resp.Redact(&RedactionPolicy{Hash: paths("user.id")})
+		require.NoError(t, err)
+		keyed, err := resp.Redact(&RedactionPolicy{Hash: paths("user.id"), HashKey: []byte("k")})
+		require.NoError(t, err)
+
+		plainID, err := plain.PeekStringByPath("user", "id")
+		require.NoError(t, err)
+		keyedID, err := keyed.PeekStringByPath("user", "id")
+		require.NoError(t, err)
+		assert.NotEqual(t, plainID, keyedID)
+		assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, keyedID)
+	})
+
+	t.Run("Projection", func(t *testing.T) {
+		resp := mustDecodeResponse(t, redactTestResponse)
+
+		redacted, err := resp.Redact(&RedactionPolicy{
+			Keep: paths("user.name", "user.email", "txs[?(@.amount > 10)]", "missing"),
+			Mask: paths("user.email"),
+		})
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{
+			"user":{"email":"[REDACTED]","name":"Alice"},
+			"txs":[{"to":"carol","amount":50}]
+		}`, string(redacted.RawResult()))
+	})
+
+	t.Run("Projection selecting nothing", func(t *testing.T) {
+		resp := mustDecodeResponse(t, redactTestResponse)
+
+		redacted, err := resp.Redact(&RedactionPolicy{Keep: paths("missing")})
+		require.NoError(t, err)
+		assert.Equal(t, "null", string(redacted.RawResult()))
+	})
+
+	t.Run("Removing the root", func(t *testing.T) {
+		resp := mustDecodeResponse(t, redactTestResponse)
+
+		redacted, err := resp.Redact(&RedactionPolicy{Remove: paths("$")})
+		require.NoError(t, err)
+		assert.Equal(t, "null", string(redacted.RawResult()))
+	})
+
+	t.Run("Paths not present are ignored", func(t *testing.T) {
+		resp := mustDecodeResponse(t, `{"jsonrpc":"2.0","id":1,"result":"plain"}`)
+
+		redacted, err := resp.Redact(&RedactionPolicy{Mask: paths("a.b", "[0]")})
+		require.NoError(t, err)
+		assert.Equal(t, `"plain"`, string(redacted.RawResult()))
+	})
+
+	t.Run("Error responses are cloned unchanged", func(t *testing.T) {
+		resp := NewErrorResponse(1, &Error{Code: -32000, Message: "failed"})
+
+		redacted, err := resp.Redact(&RedactionPolicy{Mask: paths("a")})
+		require.NoError(t, err)
+		assert.True(t, resp.Equals(redacted))
+	})
+
+	t.Run("Nil policy", func(t *testing.T) {
+		resp := mustDecodeResponse(t, redactTestResponse)
+
+		_, err := resp.Redact(nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestRequest_RedactParams(t *testing.T) {
+	t.Run("Decoded request", func(t *testing.T) {
+		req, err := DecodeRequest([]byte(`{"jsonrpc":"2.0","id":7,"method":"login",
+			"params":{"user":"alice","password":"hunter2"}}`))
+		require.NoError(t, err)
+
+		redacted, err := req.RedactParams(&RedactionPolicy{Mask: paths("password")})
+		require.NoError(t, err)
+
+		assert.Equal(t, req.ID, redacted.ID)
+		assert.Equal(t, "login", redacted.Method)
+		assert.JSONEq(t, `{"user":"alice","password":"[REDACTED]"}`, string(redacted.RawParams()))
+		assert.JSONEq(t, `{"user":"alice","password":"hunter2"}`, string(req.RawParams()))
+	})
+
+	t.Run("Params from Go values", func(t *testing.T) {
+		req := NewRequestWithID("send", []any{"0xabc", map[string]any{"key": "secret"}}, 1)
+
+		redacted, err := req.RedactParams(&RedactionPolicy{Remove: paths("[1].key")})
+		require.NoError(t, err)
+
+		data, err := redacted.MarshalJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"method":"send","params":["0xabc",{}]}`,
+			string(data))
+	})
+
+	t.Run("No params", func(t *testing.T) {
+		req := NewNotification("ping", nil)
+
+		redacted, err := req.RedactParams(&RedactionPolicy{Mask: paths("a")})
+		require.NoError(t, err)
+		assert.True(t, redacted.IsNotification())
+		assert.Nil(t, redacted.RawParams())
+	})
+
+	t.Run("Null params", func(t *testing.T) {
+		for name, req := range map[string]*Request{
+			"Raw null": {
+				JSONRPC: jsonRPCVersion, ID: Int64ID(1), Method: "m", rawParams: []byte("null"),
+			},
+			"Nil slice":    NewRequestWithID("m", []string(nil), 1),
+			"Nothing kept": NewRequestWithID("m", map[string]any{"a": 1}, 1),
+		} {
+			redacted, err := req.RedactParams(&RedactionPolicy{Keep: paths("b")})
+			require.NoError(t, err, name)
+			assert.Nil(t, redacted.RawParams(), name)
+
+			data, err := redacted.MarshalJSON()
+			require.NoError(t, err, name)
+			assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"method":"m"}`, string(data), name)
+		}
+	})
+}
@@ -7,58 +7,108 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
+
+	"github.com/bytedance/sonic/ast"
 )
 
 // Request is a struct for a JSON-RPC request. It conforms to the JSON-RPC 2.0 specification except
 // that the ID field is allowed to be fractional.
+//
+// The ID field keeps the exact encoding of the id member. A request without an id member is a
+// notification, while an explicit null id is kept as such.
+//
+// The params field is kept as raw JSON when a Request is decoded, and is only materialized into Go
+// values when accessed through Params. UnmarshalParams and MarshalJSON work directly on the raw
+// bytes, which avoids decode/re-encode round trips and preserves large numbers exactly.
 type Request struct {
 	JSONRPC string `json:"jsonrpc"`
-	ID      any    `json:"id,omitempty"`
+	ID      ID     `json:"id,omitempty"`
 	Method  string `json:"method"`
-	Params  any    `json:"params,omitempty"`
+
+	// params holds the Go value of the params field, either as supplied by a constructor or as
+	// lazily materialized from rawParams.
+	params any
+
+	// rawParams holds the raw params bytes retained from decoding.
+	rawParams json.RawMessage
+
+	// rawParamsErr records why raw params supplied to NewRequestWithRawParams are invalid.
+	rawParamsErr error
+
+	// encodedParams caches the encoded form of params for requests built from Go values.
+	encodedParams    json.RawMessage
+	encodedParamsErr error
+
+	// One-time initialization guards for lazy params materialization and encoding
+	paramsOnce       sync.Once
+	encodeParamsOnce sync.Once
+
+	// AST node caching for efficient params field access
+	paramsASTNode  ast.Node
+	paramsASTOnce  sync.Once
+	paramsASTMutex sync.RWMutex
+	paramsASTErr   error
 }
 
-// NewRequest creates a JSON-RPC 2.0 request with an auto-generated ID.
+// NewRequest creates a JSON-RPC 2.0 request with an ID from the package-wide IDGenerator.
+// See SetIDGenerator.
 func NewRequest(method string, params any) *Request {
 	return &Request{
 		JSONRPC: jsonRPCVersion,
-		ID:      RandomJSONRPCID(),
+		ID:      nextID(),
 		Method:  method,
-		Params:  params,
+		params:  params,
 	}
 }
 
-// NewRequestWithID creates a JSON-RPC 2.0 request with a specific ID.
+// NewRequestWithID creates a JSON-RPC 2.0 request with a specific ID. The id can be an ID or any
+// value accepted by NewID; unsupported values are reported by Validate.
 func NewRequestWithID(method string, params any, id any) *Request {
 	return &Request{
 		JSONRPC: jsonRPCVersion,
-		ID:      id,
+		ID:      toID(id),
 		Method:  method,
-		Params:  params,
+		params:  params,
 	}
 }
 
+// NewRequestWithRawParams creates a JSON-RPC 2.0 request with an auto-generated ID and params that
+// are already JSON-encoded. The raw params are written as-is when the request is marshaled, and
+// must encode a JSON array or object. They are checked once here, and if they are malformed or
+// encode any other value, the error is reported by Validate and by encoding the request.
+func NewRequestWithRawParams(method string, rawParams json.RawMessage) *Request {
+	req := &Request{
+		JSONRPC:   jsonRPCVersion,
+		ID:        nextID(),
+		Method:    method,
+		rawParams: bytes.TrimSpace(rawParams),
+	}
+
+	if len(req.rawParams) > 0 {
+		switch {
+		case !isStructuredJSON(req.rawParams):
+			req.rawParamsErr = errors.New(errInvalidParamsType)
+		case !getSonicAPI().Valid(req.rawParams):
+			req.rawParamsErr = errors.New("raw params are not valid JSON")
+		}
+	}
+
+	return req
+}
+
 // NewNotification creates a JSON-RPC 2.0 notification (request without ID).
 func NewNotification(method string, params any) *Request {
 	return &Request{
 		JSONRPC: jsonRPCVersion,
 		Method:  method,
-		Params:  params,
+		params:  params,
 	}
 }
 
-// IDString returns the ID as a string.
+// IDString returns the ID as a string. See ID.String for details.
 func (r *Request) IDString() string {
-	switch id := r.ID.(type) {
-	case string:
-		return id
-	case int64:
-		return fmt.Sprintf("%d", id)
-	case float64:
-		return formatFloat64ID(id)
-	default:
-		return ""
-	}
+	return r.ID.String()
 }
 
 // IsEmpty returns whether the Request is empty. A request is considered empty if the method field
@@ -75,30 +125,103 @@ func (r *Request) IsEmpty() bool {
 	return false
 }
 
-// IsNotification returns true if this is a notification (no ID expected).
+// IsNotification returns true if this is a notification, i.e. the id member is absent. A request
+// with an explicit null id is not a notification.
 func (r *Request) IsNotification() bool {
-	return r.ID == nil
+	return r.ID.IsAbsent()
+}
+
+// Params returns the params of the request as Go values. For decoded requests, the raw params are
+// unmarshaled on first access into []any or map[string]any and cached for subsequent calls.
+//
+// Use UnmarshalParams to decode params into a typed destination instead.
+func (r *Request) Params() any {
+	r.paramsOnce.Do(func() {
+		if r.params != nil || len(r.rawParams) == 0 {
+			return
+		}
+		var params any
+		// Ignore error - validation happens during decode
+		if err := getSonicAPI().Unmarshal(r.rawParams, &params); err == nil {
+			r.params = params
+		}
+	})
+	return r.params
+}
+
+// RawParams returns the raw JSON-encoded params retained from decoding or supplied through
+// NewRequestWithRawParams, or nil otherwise. The returned slice must not be modified.
+func (r *Request) RawParams() json.RawMessage {
+	return r.rawParams
 }
 
-// MarshalJSON marshals the Request to a JSON byte slice.
-func (r *Request) MarshalJSON() ([]byte, error) {
-	err := r.Validate()
+// WithID returns a copy of the request with the supplied ID while leaving the original untouched.
+// The copy holds the encoded params of the original, so they are not re-encoded when the copy is
+// marshaled. The id can be an ID or any value accepted by NewID, where nil results in a
+// notification.
+func (r *Request) WithID(newID any) (*Request, error) {
+	if r == nil {
+		return nil, errors.New("cannot update id on nil request")
+	}
+
+	id, err := NewID(newID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id: %w", err)
+	}
+
+	params, err := r.getParamsBytes()
 	if err != nil {
 		return nil, err
 	}
 
-	type alias Request // Avoid infinite recursion by using an alias
-	return getSonicAPI().Marshal((*alias)(r))
+	return &Request{
+		JSONRPC:   r.JSONRPC,
+		ID:        id,
+		Method:    r.Method,
+		rawParams: params,
+	}, nil
+}
+
+// Reset clears all fields of the Request, including cached params, so that it can be reused.
+//
+// Reset must not be called while the Request is in use by other goroutines.
+func (r *Request) Reset() {
+	if r == nil {
+		return
+	}
+
+	r.JSONRPC = ""
+	r.ID = ID{}
+	r.Method = ""
+	r.params = nil
+	r.rawParams = nil
+	r.rawParamsErr = nil
+	r.encodedParams = nil
+	r.encodedParamsErr = nil
+	r.paramsOnce = sync.Once{}
+	r.encodeParamsOnce = sync.Once{}
+	r.resetParamsAST()
 }
 
 // String returns a string representation of the JSON-RPC request.
 // Note: implements the fmt.Stringer interface.
 func (r *Request) String() string {
-	return fmt.Sprintf("ID: %v, Method: %s", r.ID, r.Method)
+	return fmt.Sprintf("ID: %s, Method: %s", formatIDForString(r.ID), r.Method)
 }
 
 // Validate checks if the JSON-RPC request conforms to the JSON-RPC specification.
+//
+// Params may be any value whose JSON encoding is an array or an object, e.g. a struct, a map or a
+// typed slice. Values other than []any and map[string]any are marshaled to check their encoding.
 func (r *Request) Validate() error {
+	if err := r.validateEnvelope(); err != nil {
+		return err
+	}
+	return r.validateParams()
+}
+
+// validateEnvelope checks the jsonrpc, method and id fields of the request.
+func (r *Request) validateEnvelope() error {
 	if r == nil {
 		return errors.New("request is nil")
 	}
@@ -114,21 +237,44 @@ func (r *Request) Validate() error {
 		return errors.New("method names starting with 'rpc.' are reserved by JSON-RPC 2.0 spec")
 	}
 
-	switch r.ID.(type) {
-	case nil, string, int64, float64:
-	default:
-		return errors.New("id field must be a string or a number")
+	if err := r.ID.validate(); err != nil {
+		return err
 	}
-	switch r.Params.(type) {
+
+	return nil
+}
+
+// validateParams checks that the params field encodes a JSON array or object, or is absent.
+func (r *Request) validateParams() error {
+	if len(r.rawParams) > 0 {
+		if r.rawParamsErr != nil {
+			return r.rawParamsErr
+		}
+		if !isStructuredJSON(r.rawParams) {
+			return errors.New(errInvalidParamsType)
+		}
+		return nil
+	}
+
+	switch r.params.(type) {
 	case nil, []any, map[string]any:
-	default:
-		return errors.New("params field must be either an array, an object, or nil")
+		return nil
+	}
+
+	// Check the first byte of the encoded form for any other type
+	params, err := r.getParamsBytes()
+	if err != nil {
+		return err
+	}
+	if params != nil && !isStructuredJSON(params) {
+		return errors.New(errInvalidParamsType)
 	}
 
 	return nil
 }
 
-// UnmarshalJSON unmarshals a JSON-RPC request from a JSON byte slice.
+// UnmarshalJSON unmarshals a JSON-RPC request from a JSON byte slice. Failures are reported as a
+// *DecodeError classifying the failure with the JSON-RPC error code to respond with.
 func (r *Request) UnmarshalJSON(data []byte) error {
 	// Auxiliary type mapping to the Request structure, but with raw fields
 	type requestAux struct {
@@ -140,125 +286,114 @@ func (r *Request) UnmarshalJSON(data []byte) error {
 
 	var aux requestAux
 	if err := getSonicAPI().Unmarshal(data, &aux); err != nil {
-		return err
+		if !getSonicAPI().Valid(data) {
+			return newDecodeError(ParseError, "", err)
+		}
+		return newDecodeError(InvalidRequest, detailInvalidRequest, err)
 	}
 
 	if aux.JSONRPC != jsonRPCVersion {
-		return errors.New("jsonrpc field is required to be exactly \"2.0\"")
+		return newDecodeError(InvalidRequest, detailInvalidVersion, errors.New(detailInvalidVersion))
 	}
 	r.JSONRPC = aux.JSONRPC
 
 	if aux.Method == "" {
-		return errors.New("method field is required")
+		return newDecodeError(InvalidRequest, detailMissingMethod, errors.New(detailMissingMethod))
 	}
 	r.Method = aux.Method
 
 	// Unmarshal and validate the id field
-	id, err := unmarshalRequestID(aux.ID)
+	id, err := ParseID(aux.ID)
 	if err != nil {
-		return err
+		return newDecodeError(InvalidRequest, detailInvalidID, err)
 	}
 	r.ID = id
 
-	// Unmarshal and validate the params field
-	params, err := unmarshalRequestParams(aux.Params)
+	// Validate and retain the raw params field
+	rawParams, err := validateRequestParams(aux.Params)
 	if err != nil {
-		return err
+		return newDecodeError(InvalidParams, detailInvalidParams, err)
 	}
-	r.Params = params
+	r.params = nil
+	r.rawParams = rawParams
+	r.encodedParams = nil
+	r.encodedParamsErr = nil
+	r.paramsOnce = sync.Once{}
+	r.encodeParamsOnce = sync.Once{}
+	r.resetParamsAST()
 
 	return nil
 }
 
-// unmarshalRequestID unmarshals and normalizes the ID field from raw JSON.
-func unmarshalRequestID(rawID json.RawMessage) (any, error) {
-	if len(rawID) == 0 {
+// validateRequestParams validates the raw params field and returns the bytes to retain. Only
+// arrays and objects are accepted; null and empty strings are treated as absent params.
+func validateRequestParams(rawParams json.RawMessage) (json.RawMessage, error) {
+	trimmed := bytes.TrimSpace(rawParams)
+	if len(trimmed) == 0 {
 		return nil, nil
 	}
 
-	var id any
-	if err := getSonicAPI().Unmarshal(rawID, &id); err != nil {
-		return nil, fmt.Errorf("invalid id field: %w", err)
+	if isStructuredJSON(trimmed) {
+		return trimmed, nil
 	}
 
-	// If the value is "null", id will be nil
-	if id == nil {
+	// Treat null and empty strings as nil
+	if string(trimmed) == "null" || string(trimmed) == `""` {
 		return nil, nil
 	}
 
-	switch v := id.(type) {
-	case float64:
-		// JSON numbers are unmarshalled as float64, so an explicit integer check is needed
-		if v != float64(int64(v)) {
-			return v, nil
-		}
-		return int64(v), nil
-	case string:
-		if v == "" {
-			return nil, nil
-		}
-		return v, nil
-	default:
-		return nil, errors.New("id field must be a string or a number")
-	}
+	return nil, errors.New(errInvalidParamsType)
 }
 
-// unmarshalRequestParams unmarshals and validates the params field from raw JSON.
-func unmarshalRequestParams(rawParams json.RawMessage) (any, error) {
-	if len(rawParams) == 0 {
-		return nil, nil
-	}
-
-	var params any
-	if err := getSonicAPI().Unmarshal(rawParams, &params); err != nil {
-		return nil, fmt.Errorf("invalid params field: %w", err)
-	}
-
-	// Accept only arrays or objects.
-	switch params.(type) {
-	case []any, map[string]any, nil:
-		return params, nil
-	case string:
-		// Treat empty strings as nil
-		if params != "" {
-			return nil, errors.New("params field must be either an array, an object, or nil")
-		}
-		return nil, nil
-	default:
-		return nil, errors.New("params field must be either an array, an object, or nil")
-	}
+// isStructuredJSON returns true if the data starts with '[' or '{', i.e. is a JSON array or
+// object. The data is expected to have been validated as JSON beforehand.
+func isStructuredJSON(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{')
 }
 
-// UnmarshalParams decodes the Params field into the provided destination pointer.
+// UnmarshalParams decodes the params field into the provided destination pointer.
 // This is a convenience method for unmarshaling structured parameters.
+//
+// For decoded requests the destination is unmarshaled directly from the raw params bytes.
 func (r *Request) UnmarshalParams(dst any) error {
 	if dst == nil {
 		return errors.New("destination pointer cannot be nil")
 	}
 
-	if r.Params == nil {
-		return errors.New("request has no params field")
-	}
-
-	// Marshal params back to JSON, then unmarshal into destination
-	// This handles the conversion from any ([]any or map[string]any) to the target type
-	paramBytes, err := getSonicAPI().Marshal(r.Params)
+	paramBytes, err := r.getParamsBytes()
 	if err != nil {
-		return fmt.Errorf("failed to marshal params: %w", err)
+		return err
+	}
+	if paramBytes == nil {
+		return errors.New("request has no params field")
 	}
 
 	return getSonicAPI().Unmarshal(paramBytes, dst)
 }
 
-// DecodeRequest parses a JSON-RPC request from a byte slice.
+// DecodeRequest parses a JSON-RPC request from a byte slice. Failures are reported as a
+// *DecodeError; see NewDecodeErrorResponse for building the matching error response.
 func DecodeRequest(data []byte) (*Request, error) {
-	if len(bytes.TrimSpace(data)) == 0 {
-		return nil, errors.New(errEmptyData)
-	}
 	req := &Request{}
-	err := req.UnmarshalJSON(data)
-	if err != nil {
+	if err := DecodeRequestInto(req, data); err != nil {
 		return nil, err
 	}
 	return req, nil
 }
+
+// DecodeRequestInto parses a JSON-RPC request from a byte slice into an existing Request, which is
+// reset before decoding. This allows reusing Request objects, e.g. ones obtained from
+// AcquireRequest. If decoding fails, the contents of req are unspecified until it is reset or
+// decoded into again.
+func DecodeRequestInto(req *Request, data []byte) error {
+	if req == nil {
+		return errors.New("cannot decode into nil request")
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return newDecodeError(ParseError, "", errors.New(errEmptyData))
+	}
+
+	req.Reset()
+	return req.UnmarshalJSON(data)
+}
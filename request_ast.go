@@ -0,0 +1,157 @@
+package jsonrpc
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/bytedance/sonic/ast"
+)
+
+// PeekParamStringByPath traverses the params JSON using sonic's AST to extract a string field
+// without unmarshaling the params. This lets routers read e.g. a block tag or chain id cheaply.
+//
+// The path is specified as a sequence of object keys and array indices. An example reading the
+// second positional parameter:
+//
+//	blockTag, err := request.PeekParamStringByPath(1)
+//
+// The AST node is lazily built on first call and cached for subsequent calls. Params supplied as
+// Go values are encoded once to build it.
+func (r *Request) PeekParamStringByPath(path ...any) (string, error) {
+	node, err := r.peekParamNode(path...)
+	if err != nil {
+		return "", err
+	}
+	return nodeString(&node)
+}
+
+// PeekParamBytesByPath returns the raw JSON bytes of a field of the params without unmarshaling
+// the params, e.g. to unmarshal a single nested object separately.
+//
+//	filter, err := request.PeekParamBytesByPath(0, "filter")
+func (r *Request) PeekParamBytesByPath(path ...any) ([]byte, error) {
+	node, err := r.peekParamNode(path...)
+	if err != nil {
+		return nil, err
+	}
+	return nodeBytes(&node)
+}
+
+// PeekParamInt64ByPath extracts an integer field from the params, with the same conversion rules
+// as Response.PeekInt64ByPath.
+func (r *Request) PeekParamInt64ByPath(path ...any) (int64, error) {
+	node, err := r.peekParamNode(path...)
+	if err != nil {
+		return 0, err
+	}
+	return nodeInt64(&node)
+}
+
+// PeekParamUint64ByPath extracts an unsigned integer field from the params, with the same
+// conversion rules as Response.PeekUint64ByPath.
+func (r *Request) PeekParamUint64ByPath(path ...any) (uint64, error) {
+	node, err := r.peekParamNode(path...)
+	if err != nil {
+		return 0, err
+	}
+	return nodeUint64(&node)
+}
+
+// PeekParamFloat64ByPath extracts a numeric field from the params as a float64.
+func (r *Request) PeekParamFloat64ByPath(path ...any) (float64, error) {
+	node, err := r.peekParamNode(path...)
+	if err != nil {
+		return 0, err
+	}
+	return nodeFloat64(&node)
+}
+
+// PeekParamBoolByPath extracts a boolean field from the params.
+func (r *Request) PeekParamBoolByPath(path ...any) (bool, error) {
+	node, err := r.peekParamNode(path...)
+	if err != nil {
+		return false, err
+	}
+	return nodeBool(&node)
+}
+
+// PeekParamLenByPath returns the number of elements of an array, or the number of members of an
+// object, at the given path of the params. With an empty path it returns the number of params.
+func (r *Request) PeekParamLenByPath(path ...any) (int, error) {
+	node, err := r.peekParamNode(path...)
+	if err != nil {
+		return 0, err
+	}
+
+	return nodeLen(&node)
+}
+
+// PeekParamExists returns true if the params contain a value at the given path.
+func (r *Request) PeekParamExists(path ...any) bool {
+	_, err := r.peekParamNode(path...)
+	return err == nil
+}
+
+// peekParamNode returns the params AST node at the given path, or the root node if the path is
+// empty.
+func (r *Request) peekParamNode(path ...any) (ast.Node, error) {
+	node, err := r.getParamsASTNode()
+	if err != nil {
+		return ast.Node{}, err
+	}
+
+	// Navigate to the requested path
+	if len(path) > 0 {
+		targetNode := node.GetByPath(path...)
+		if targetNode == nil || !targetNode.Exists() {
+			return ast.Node{}, errors.New("path not found")
+		}
+		node = *targetNode
+	}
+
+	return node, nil
+}
+
+// buildParamsASTNode lazily builds the AST node for the params field.
+func (r *Request) buildParamsASTNode() {
+	params, err := r.getParamsBytes()
+	if err != nil {
+		r.paramsASTErr = err
+		return
+	}
+	if len(params) == 0 {
+		r.paramsASTErr = errors.New("request has no params field")
+		return
+	}
+
+	node, err := ast.NewSearcher(string(params)).GetByPath()
+	if err != nil {
+		r.paramsASTErr = fmt.Errorf("failed to build AST node: %w", err)
+		return
+	}
+	r.paramsASTNode = node
+}
+
+// getParamsASTNode returns the cached params AST node, building it if necessary.
+func (r *Request) getParamsASTNode() (ast.Node, error) {
+	r.paramsASTOnce.Do(r.buildParamsASTNode)
+
+	r.paramsASTMutex.RLock()
+	defer r.paramsASTMutex.RUnlock()
+
+	if r.paramsASTErr != nil {
+		return ast.Node{}, r.paramsASTErr
+	}
+
+	return r.paramsASTNode, nil
+}
+
+// resetParamsAST clears the cached params AST node.
+func (r *Request) resetParamsAST() {
+	r.paramsASTMutex.Lock()
+	r.paramsASTNode = ast.Node{}
+	r.paramsASTErr = nil
+	r.paramsASTMutex.Unlock()
+	r.paramsASTOnce = sync.Once{}
+}
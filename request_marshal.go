@@ -0,0 +1,141 @@
+package jsonrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// requestStructureOverhead is the size of {"jsonrpc":"2.0","id":,"method":"","params":}
+	requestStructureOverhead = 48
+)
+
+// MarshalJSON marshals the Request to a JSON byte slice.
+//
+// Raw params retained from decoding are written as-is, without being re-encoded. Params supplied
+// as Go values are encoded once and cached for subsequent calls.
+func (r *Request) MarshalJSON() ([]byte, error) {
+	params, err := r.validatedParamsBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	dst := make([]byte, 0, requestStructureOverhead+len(r.Method)+len(params))
+	return r.appendJSON(dst, params), nil
+}
+
+// AppendJSON appends the JSON encoding of the Request to dst and returns the extended buffer. This
+// allows serializing into caller-owned, e.g. pooled, buffers without intermediate allocations.
+// If the request is invalid, dst is returned unchanged along with the error.
+func (r *Request) AppendJSON(dst []byte) ([]byte, error) {
+	params, err := r.validatedParamsBytes()
+	if err != nil {
+		return dst, err
+	}
+	return r.appendJSON(dst, params), nil
+}
+
+// WriteTo implements io.WriterTo for streaming serialization of the Request. The params are
+// written directly from their raw or cached encoded form without being copied into an
+// intermediate buffer.
+func (r *Request) WriteTo(w io.Writer) (n int64, err error) {
+	params, err := r.validatedParamsBytes()
+	if err != nil {
+		return 0, err
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	header := r.appendHeader((*buf)[:0])
+	*buf = header // Keep any grown capacity for reuse
+
+	var total int64
+	if err = writeBytes(w, header, &total); err != nil {
+		return total, err
+	}
+
+	if params != nil {
+		if err = writeString(w, `,"params":`, &total); err != nil {
+			return total, err
+		}
+		if err = writeBytes(w, params, &total); err != nil {
+			return total, err
+		}
+	}
+
+	if err = writeString(w, `}`, &total); err != nil {
+		return total, err
+	}
+
+	return total, nil
+}
+
+// validatedParamsBytes validates the request and returns the encoded params. Params are encoded
+// once and validated on the encoded form.
+func (r *Request) validatedParamsBytes() (json.RawMessage, error) {
+	if err := r.validateEnvelope(); err != nil {
+		return nil, err
+	}
+
+	params, err := r.getParamsBytes()
+	if err != nil {
+		return nil, err
+	}
+	if params != nil && !isStructuredJSON(params) {
+		return nil, errors.New(errInvalidParamsType)
+	}
+
+	return params, nil
+}
+
+// appendJSON appends the full request encoding to dst using the already encoded params.
+func (r *Request) appendJSON(dst []byte, params json.RawMessage) []byte {
+	out := r.appendHeader(dst)
+	if params != nil {
+		out = append(out, `,"params":`...)
+		out = append(out, params...)
+	}
+
+	return append(out, '}')
+}
+
+// appendHeader appends the jsonrpc, id and method fields to dst, leaving the object open.
+func (r *Request) appendHeader(dst []byte) []byte {
+	dst = append(dst, `{"jsonrpc":"2.0"`...)
+
+	if !r.ID.IsAbsent() {
+		dst = append(dst, `,"id":`...)
+		dst = r.ID.appendJSON(dst)
+	}
+
+	dst = append(dst, `,"method":`...)
+	return appendJSONString(dst, r.Method)
+}
+
+// getParamsBytes returns the JSON-encoded params. Uses the retained raw params if available, and
+// otherwise encodes the Go value once and caches the result. Params encoding to null, e.g. nil
+// slices or pointers, are treated as absent.
+func (r *Request) getParamsBytes() (json.RawMessage, error) {
+	if len(r.rawParams) > 0 {
+		return r.rawParams, r.rawParamsErr
+	}
+	if r.params == nil {
+		return nil, nil
+	}
+
+	r.encodeParamsOnce.Do(func() {
+		paramBytes, err := getSonicAPI().Marshal(r.params)
+		if err != nil {
+			r.encodedParamsErr = fmt.Errorf("failed to marshal params: %w", err)
+			return
+		}
+		if string(paramBytes) != "null" {
+			r.encodedParams = paramBytes
+		}
+	})
+
+	return r.encodedParams, r.encodedParamsErr
+}
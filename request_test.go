@@ -3,6 +3,10 @@
 package jsonrpc
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
 	"sync"
 	"testing"
 
@@ -12,31 +16,31 @@ import (
 
 func TestRequest_IDString(t *testing.T) {
 	t.Run("String ID", func(t *testing.T) {
-		req := &Request{ID: "abc"}
+		req := &Request{ID: StringID("abc")}
 		assert.Equal(t, "abc", req.IDString())
 	})
 
 	t.Run("Int64 ID", func(t *testing.T) {
-		req := &Request{ID: int64(123)}
+		req := &Request{ID: Int64ID(123)}
 		assert.Equal(t, "123", req.IDString())
 	})
 
 	t.Run("Float64 ID", func(t *testing.T) {
-		req := &Request{ID: float64(123.456)}
+		req := &Request{ID: Float64ID(123.456)}
 		assert.Equal(t, "123.456", req.IDString())
 	})
 
 	t.Run("Float64 ID, with integer value", func(t *testing.T) {
-		resp := &Request{ID: float64(25.0)}
+		resp := &Request{ID: Float64ID(25.0)}
 		assert.Equal(t, "25.0", resp.IDString())
 	})
 	t.Run("Nil ID", func(t *testing.T) {
-		req := &Request{ID: nil}
+		req := &Request{ID: ID{}}
 		assert.Equal(t, "", req.IDString())
 	})
 
 	t.Run("Unknown type ID", func(t *testing.T) {
-		req := &Request{ID: []int{1, 2, 3}}
+		req := &Request{ID: toID([]int{1, 2, 3})}
 		assert.Equal(t, "", req.IDString())
 	})
 }
@@ -73,30 +77,30 @@ func TestRequest_MarshalJSON(t *testing.T) {
 			{
 				name: "With int ID",
 				req: &Request{JSONRPC: "2.0", Method: "testMethod",
-					Params: []any{"0x123"}, ID: int64(99)},
+					params: []any{"0x123"}, ID: Int64ID(99)},
 				expected: `{"jsonrpc":"2.0","id":99,"method":"testMethod","params":["0x123"]}`,
 			},
 			{
 				name: "With string ID",
 				req: &Request{JSONRPC: "2.0", Method: "eth_getBalance",
-					Params: []any{}, ID: "abc"},
+					params: []any{}, ID: StringID("abc")},
 				expected: `{"jsonrpc":"2.0","id":"abc","method":"eth_getBalance","params":[]}`,
 			},
 			{
 				name:     "With nil Params",
-				req:      &Request{JSONRPC: "2.0", Method: "eth_chainId", ID: "abc"},
+				req:      &Request{JSONRPC: "2.0", Method: "eth_chainId", ID: StringID("abc")},
 				expected: `{"jsonrpc":"2.0","id":"abc","method":"eth_chainId"}`,
 			},
 			{
 				name: "With empty Params array",
 				req: &Request{JSONRPC: "2.0", Method: "eth_chainId",
-					Params: []any{}, ID: "abc"},
+					params: []any{}, ID: StringID("abc")},
 				expected: `{"jsonrpc":"2.0","id":"abc","method":"eth_chainId","params":[]}`,
 			},
 			{
 				name: "With object Params",
 				req: &Request{JSONRPC: "2.0", Method: "eth_getBalance",
-					Params: map[string]any{"address": "0x123"}, ID: "abc"},
+					params: map[string]any{"address": "0x123"}, ID: StringID("abc")},
 				expected: `{"jsonrpc":"2.0","id":"abc","method":"eth_getBalance",` +
 					`"params":{"address":"0x123"}}`,
 			},
@@ -134,7 +138,7 @@ func TestRequest_MarshalJSON(t *testing.T) {
 			},
 			{
 				name: "Invalid ID type",
-				req:  &Request{JSONRPC: "2.0", Method: "testMethod", ID: []int{1, 2, 3}},
+				req:  &Request{JSONRPC: "2.0", Method: "testMethod", ID: toID([]int{1, 2, 3})},
 			},
 		}
 
@@ -149,31 +153,31 @@ func TestRequest_MarshalJSON(t *testing.T) {
 
 func TestRequest_String(t *testing.T) {
 	t.Run("With int ID", func(t *testing.T) {
-		req := &Request{JSONRPC: "2.0", Method: "testMethod", Params: []any{"0x123"}, ID: int64(99)}
+		req := &Request{JSONRPC: "2.0", Method: "testMethod", params: []any{"0x123"}, ID: Int64ID(99)}
 		expected := "ID: 99, Method: testMethod"
 		assert.Equal(t, expected, req.String())
 	})
 
 	t.Run("With string ID", func(t *testing.T) {
-		req := &Request{JSONRPC: "2.0", Method: "eth_getBalance", Params: []any{}, ID: "abc"}
+		req := &Request{JSONRPC: "2.0", Method: "eth_getBalance", params: []any{}, ID: StringID("abc")}
 		expected := "ID: abc, Method: eth_getBalance"
 		assert.Equal(t, expected, req.String())
 	})
 
 	t.Run("With nil ID", func(t *testing.T) {
-		req := &Request{JSONRPC: "2.0", Method: "eth_chainId", ID: nil}
+		req := &Request{JSONRPC: "2.0", Method: "eth_chainId", ID: ID{}}
 		expected := "ID: <nil>, Method: eth_chainId"
 		assert.Equal(t, expected, req.String())
 	})
 
 	t.Run("With float ID", func(t *testing.T) {
-		req := &Request{JSONRPC: "2.0", Method: "testMethod", ID: float64(123.456)}
+		req := &Request{JSONRPC: "2.0", Method: "testMethod", ID: Float64ID(123.456)}
 		expected := "ID: 123.456, Method: testMethod"
 		assert.Equal(t, expected, req.String())
 	})
 
 	t.Run("With empty Method", func(t *testing.T) {
-		req := &Request{JSONRPC: "2.0", Method: "", ID: "abc"}
+		req := &Request{JSONRPC: "2.0", Method: "", ID: StringID("abc")}
 		expected := "ID: abc, Method: "
 		assert.Equal(t, expected, req.String())
 	})
@@ -182,21 +186,21 @@ func TestRequest_String(t *testing.T) {
 func TestRequest_UnmarshalJSON(t *testing.T) {
 	t.Run("Valid JSON with int ID", func(t *testing.T) {
 		data := []byte(`{"jsonrpc":"2.0","method":"test","params":["0x123"],"id":99}`)
-		expected := Request{JSONRPC: "2.0", Method: "test", Params: []any{"0x123"}, ID: int64(99)}
+		expected := Request{JSONRPC: "2.0", Method: "test", params: []any{"0x123"}, ID: Int64ID(99)}
 
 		var result Request
 		err := result.UnmarshalJSON(data)
 		assert.NoError(t, err, "Unexpected error")
 		assert.Equal(t, expected.JSONRPC, result.JSONRPC)
 		assert.Equal(t, expected.Method, result.Method)
-		assert.Equal(t, expected.Params, result.Params)
+		assert.Equal(t, expected.Params(), result.Params())
 		assert.Equal(t, expected.ID, result.ID)
-		assert.IsType(t, int64(0), result.ID)
+		assert.Equal(t, IDKindNumber, result.ID.Kind())
 	})
 
 	t.Run("Valid JSON with float ID", func(t *testing.T) {
 		data := []byte(`{"jsonrpc":"2.0","method":"test","id":33.3}`)
-		expected := Request{JSONRPC: "2.0", Method: "test", ID: float64(33.3)}
+		expected := Request{JSONRPC: "2.0", Method: "test", ID: Float64ID(33.3)}
 
 		var result Request
 		err := result.UnmarshalJSON(data)
@@ -204,53 +208,74 @@ func TestRequest_UnmarshalJSON(t *testing.T) {
 		assert.Equal(t, expected.JSONRPC, result.JSONRPC)
 		assert.Equal(t, expected.Method, result.Method)
 		assert.Equal(t, expected.ID, result.ID)
-		assert.IsType(t, float64(0), result.ID)
+		assert.Equal(t, IDKindNumber, result.ID.Kind())
 	})
 
 	t.Run("Valid JSON with string ID", func(t *testing.T) {
 		data := []byte(`{"jsonrpc":"2.0","method":"eth_getBalance","params":[],"id":"abc"}`)
-		expected := Request{JSONRPC: "2.0", Method: "eth_getBalance", ID: "abc"}
+		expected := Request{JSONRPC: "2.0", Method: "eth_getBalance", ID: StringID("abc")}
 
 		var result Request
 		err := result.UnmarshalJSON(data)
 		assert.NoError(t, err, "Unexpected error")
 		assert.Equal(t, expected.JSONRPC, result.JSONRPC)
 		assert.Equal(t, expected.Method, result.Method)
-		assert.Empty(t, result.Params)
-		assert.IsType(t, "", result.ID)
+		assert.Empty(t, result.Params())
+		assert.Equal(t, IDKindString, result.ID.Kind())
 		assert.Equal(t, expected.ID, result.ID)
 	})
 
 	t.Run("Valid JSON with extra field", func(t *testing.T) {
 		data := []byte(`{"jsonrpc":"2.0","method":"test","id":32123,"something":"extra"}`)
-		expected := Request{JSONRPC: "2.0", Method: "test", ID: int64(32123)}
+		expected := Request{JSONRPC: "2.0", Method: "test", ID: Int64ID(32123)}
 
 		var result Request
 		err := result.UnmarshalJSON(data)
 		assert.NoError(t, err, "Unexpected error")
 		assert.Equal(t, expected.JSONRPC, result.JSONRPC)
 		assert.Equal(t, expected.Method, result.Method)
-		assert.Equal(t, expected.Params, result.Params)
+		assert.Equal(t, expected.Params(), result.Params())
 		assert.Equal(t, expected.ID, result.ID)
-		assert.IsType(t, int64(0), result.ID)
+		assert.Equal(t, IDKindNumber, result.ID.Kind())
 	})
 
-	t.Run("Empty string ID => replaced with nil", func(t *testing.T) {
+	t.Run("Empty string ID => kept as string ID", func(t *testing.T) {
 		data := []byte(`{"jsonrpc":"2.0","id":"","method":"eth_chainId"}`)
 		var req Request
 		err := req.UnmarshalJSON(data)
 		require.NoError(t, err)
-		assert.Nil(t, req.ID)
-		// If empty string, ID should be nil
-		_, ok := req.ID.(string)
-		assert.False(t, ok)
-		_, ok = req.ID.(int64)
-		assert.False(t, ok)
-		_, ok = req.ID.(float64)
-		assert.False(t, ok)
+		assert.Equal(t, StringID(""), req.ID)
+		assert.False(t, req.IsNotification())
 		assert.Equal(t, "eth_chainId", req.Method)
 	})
 
+	t.Run("Null ID => distinct from absent ID", func(t *testing.T) {
+		var withNull, withoutID Request
+		require.NoError(t, withNull.UnmarshalJSON(
+			[]byte(`{"jsonrpc":"2.0","id":null,"method":"eth_chainId"}`)))
+		require.NoError(t, withoutID.UnmarshalJSON([]byte(`{"jsonrpc":"2.0","method":"eth_chainId"}`)))
+
+		assert.True(t, withNull.ID.IsNull())
+		assert.False(t, withNull.IsNotification())
+		assert.True(t, withoutID.ID.IsAbsent())
+		assert.True(t, withoutID.IsNotification())
+	})
+
+	t.Run("Large and fractional IDs => exact round trip", func(t *testing.T) {
+		for _, data := range []string{
+			`{"jsonrpc":"2.0","id":18446744073709551616,"method":"test"}`,
+			`{"jsonrpc":"2.0","id":1.0,"method":"test"}`,
+			`{"jsonrpc":"2.0","id":null,"method":"test"}`,
+			`{"jsonrpc":"2.0","id":"a\u0062c","method":"test"}`,
+		} {
+			var req Request
+			require.NoError(t, req.UnmarshalJSON([]byte(data)))
+			marshaled, err := req.MarshalJSON()
+			require.NoError(t, err)
+			assert.Equal(t, data, string(marshaled))
+		}
+	})
+
 	t.Run("Invalid JSONRPC => error", func(t *testing.T) {
 		invalidJSONs := [][]byte{
 			// Invalid JSONRPC field
@@ -358,7 +383,7 @@ func TestRequest_Concurrency(t *testing.T) {
 		for range 2000 {
 			_ = req.IDString()
 			_ = req.Method
-			_ = req.Params
+			_ = req.Params()
 			assert.False(t, req.IsEmpty())
 
 			marshaledData, err := req.MarshalJSON()
@@ -380,8 +405,8 @@ func TestDecodeRequest(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, req)
 		assert.Equal(t, "testMethod", req.Method)
-		assert.EqualValues(t, 1, req.ID)
-		assert.Equal(t, []any{"0x123"}, req.Params)
+		assert.Equal(t, Int64ID(1), req.ID)
+		assert.Equal(t, []any{"0x123"}, req.Params())
 	})
 
 	t.Run("Unmarshal error", func(t *testing.T) {
@@ -398,3 +423,448 @@ func TestDecodeRequest(t *testing.T) {
 		require.Nil(t, req)
 	})
 }
+
+func TestRequest_Params(t *testing.T) {
+	t.Run("Constructed request returns supplied value", func(t *testing.T) {
+		params := []any{"0x123", "latest"}
+		req := NewRequestWithID("eth_getBalance", params, int64(1))
+		assert.Equal(t, params, req.Params())
+		assert.Nil(t, req.RawParams())
+	})
+
+	t.Run("Decoded request materializes lazily", func(t *testing.T) {
+		data := []byte(`{"jsonrpc":"2.0","id":1,"method":"test","params":{"key":"value"}}`)
+		req, err := DecodeRequest(data)
+		require.NoError(t, err)
+
+		assert.Nil(t, req.params, "params should not be materialized before access")
+		assert.Equal(t, map[string]any{"key": "value"}, req.Params())
+		assert.Equal(t, `{"key":"value"}`, string(req.RawParams()))
+	})
+
+	t.Run("Null and empty string params are treated as absent", func(t *testing.T) {
+		for _, data := range [][]byte{
+			[]byte(`{"jsonrpc":"2.0","id":1,"method":"test","params":null}`),
+			[]byte(`{"jsonrpc":"2.0","id":1,"method":"test","params":""}`),
+		} {
+			req, err := DecodeRequest(data)
+			require.NoError(t, err)
+			assert.Nil(t, req.Params())
+			assert.Nil(t, req.RawParams())
+		}
+	})
+}
+
+func TestRequest_UnmarshalParams(t *testing.T) {
+	t.Run("Large integers keep precision", func(t *testing.T) {
+		data := []byte(`{"jsonrpc":"2.0","id":1,"method":"test","params":[18446744073709551615]}`)
+		req, err := DecodeRequest(data)
+		require.NoError(t, err)
+
+		var params []uint64
+		require.NoError(t, req.UnmarshalParams(&params))
+		assert.Equal(t, []uint64{18446744073709551615}, params)
+	})
+
+	t.Run("Constructed request", func(t *testing.T) {
+		req := NewRequest("test", map[string]any{"name": "Alice"})
+
+		var params struct {
+			Name string `json:"name"`
+		}
+		require.NoError(t, req.UnmarshalParams(&params))
+		assert.Equal(t, "Alice", params.Name)
+	})
+
+	t.Run("No params", func(t *testing.T) {
+		req := NewRequest("test", nil)
+		var params []any
+		assert.Error(t, req.UnmarshalParams(&params))
+	})
+
+	t.Run("Nil destination", func(t *testing.T) {
+		req := NewRequest("test", []any{1})
+		assert.Error(t, req.UnmarshalParams(nil))
+	})
+}
+
+func TestRequest_MarshalJSON_RawParams(t *testing.T) {
+	data := []byte(`{"jsonrpc":"2.0","id":1,"method":"test","params":[1.000,12345678901234567890]}`)
+	req, err := DecodeRequest(data)
+	require.NoError(t, err)
+
+	marshaled, err := req.MarshalJSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(marshaled), `"params":[1.000,12345678901234567890]`,
+		"raw params should be forwarded untouched")
+}
+
+func TestRequest_TypedParams(t *testing.T) {
+	type blockParams struct {
+		Number string `json:"number"`
+		Full   bool   `json:"full"`
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		cases := []struct {
+			name     string
+			params   any
+			expected string
+		}{
+			{
+				name:     "Struct",
+				params:   blockParams{Number: "0x1", Full: true},
+				expected: `{"number":"0x1","full":true}`,
+			},
+			{
+				name:     "Struct pointer",
+				params:   &blockParams{Number: "0x2"},
+				expected: `{"number":"0x2","full":false}`,
+			},
+			{
+				name:     "Typed slice",
+				params:   []string{"a", "b"},
+				expected: `["a","b"]`,
+			},
+			{
+				name:     "Typed map",
+				params:   map[string]int{"a": 1},
+				expected: `{"a":1}`,
+			},
+			{
+				name:     "Raw message",
+				params:   json.RawMessage(`[1,2]`),
+				expected: `[1,2]`,
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				req := NewRequestWithID("test", tc.params, int64(1))
+				require.NoError(t, req.Validate())
+
+				data, err := req.MarshalJSON()
+				require.NoError(t, err)
+				assert.JSONEq(t,
+					`{"jsonrpc":"2.0","id":1,"method":"test","params":`+tc.expected+`}`,
+					string(data))
+			})
+		}
+	})
+
+	t.Run("Encoding to null is treated as absent", func(t *testing.T) {
+		var nilSlice []string
+		req := NewRequestWithID("test", nilSlice, int64(1))
+		require.NoError(t, req.Validate())
+
+		data, err := req.MarshalJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"method":"test"}`, string(data))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, params := range []any{"string", 42, true, 1.5, json.RawMessage(`"raw"`)} {
+			req := NewRequestWithID("test", params, int64(1))
+			assert.Error(t, req.Validate(), "params %v should be rejected", params)
+			_, err := req.MarshalJSON()
+			assert.Error(t, err, "params %v should fail to marshal", params)
+		}
+	})
+
+	t.Run("Unmarshal typed params", func(t *testing.T) {
+		req := NewRequest("test", blockParams{Number: "0x10", Full: true})
+
+		var params blockParams
+		require.NoError(t, req.UnmarshalParams(&params))
+		assert.Equal(t, blockParams{Number: "0x10", Full: true}, params)
+	})
+}
+
+func TestNewRequestWithRawParams(t *testing.T) {
+	t.Run("Valid raw params", func(t *testing.T) {
+		req := NewRequestWithRawParams("test", json.RawMessage(` ["0x1", true] `))
+		require.NoError(t, req.Validate())
+		assert.False(t, req.ID.IsAbsent())
+		assert.Equal(t, `["0x1", true]`, string(req.RawParams()))
+		assert.Equal(t, []any{"0x1", true}, req.Params())
+
+		data, err := req.MarshalJSON()
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"params":["0x1", true]`)
+	})
+
+	t.Run("Scalar raw params", func(t *testing.T) {
+		req := NewRequestWithRawParams("test", json.RawMessage(`12`))
+		assert.Error(t, req.Validate())
+		_, err := req.MarshalJSON()
+		assert.Error(t, err)
+	})
+
+	t.Run("Malformed raw params", func(t *testing.T) {
+		for _, raw := range []string{`[1,`, `{"a":}`, `[1] [2]`, `{"a":1}x`} {
+			req := NewRequestWithRawParams("test", json.RawMessage(raw))
+			assert.Error(t, req.Validate(), "params %s should be rejected", raw)
+
+			_, err := req.MarshalJSON()
+			assert.Error(t, err, "params %s should fail to marshal", raw)
+			_, err = req.AppendJSON(nil)
+			assert.Error(t, err)
+			_, err = req.WriteTo(io.Discard)
+			assert.Error(t, err)
+		}
+	})
+
+	t.Run("Empty raw params", func(t *testing.T) {
+		req := NewRequestWithRawParams("test", nil)
+		require.NoError(t, req.Validate())
+		assert.Nil(t, req.Params())
+	})
+}
+
+func TestRequest_AppendJSON(t *testing.T) {
+	t.Run("Appends to existing buffer", func(t *testing.T) {
+		req := NewRequestWithID("eth_getBalance", []any{"0x123", "latest"}, "abc")
+
+		dst := []byte("prefix:")
+		out, err := req.AppendJSON(dst)
+		require.NoError(t, err)
+		require.True(t, bytes.HasPrefix(out, []byte("prefix:")))
+		assert.JSONEq(t,
+			`{"jsonrpc":"2.0","id":"abc","method":"eth_getBalance","params":["0x123","latest"]}`,
+			string(out[len("prefix:"):]))
+	})
+
+	t.Run("Matches MarshalJSON", func(t *testing.T) {
+		cases := []*Request{
+			NewRequestWithID("test", nil, int64(1)),
+			NewRequestWithID("test", map[string]any{"a": 1}, float64(1.5)),
+			NewRequestWithID("te\"st<>", []any{"x"}, "id\n"),
+			NewNotification("notify", []any{1, 2}),
+		}
+
+		for _, req := range cases {
+			marshaled, err := req.MarshalJSON()
+			require.NoError(t, err)
+			appended, err := req.AppendJSON(nil)
+			require.NoError(t, err)
+			assert.Equal(t, string(marshaled), string(appended))
+			assert.True(t, json.Valid(appended), "invalid JSON: %s", appended)
+		}
+	})
+
+	t.Run("Notification omits id", func(t *testing.T) {
+		out, err := NewNotification("notify", nil).AppendJSON(nil)
+		require.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","method":"notify"}`, string(out))
+	})
+
+	t.Run("Invalid request leaves dst unchanged", func(t *testing.T) {
+		dst := []byte("prefix")
+		out, err := (&Request{JSONRPC: "1.0", Method: "test"}).AppendJSON(dst)
+		require.Error(t, err)
+		assert.Equal(t, "prefix", string(out))
+	})
+}
+
+func TestRequest_WriteTo(t *testing.T) {
+	t.Run("Decoded request with raw params", func(t *testing.T) {
+		data := `{"jsonrpc":"2.0","id":7,"method":"test","params":{"big":12345678901234567890}}`
+		req, err := DecodeRequest([]byte(data))
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		n, err := req.WriteTo(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, int64(buf.Len()), n)
+		assert.Equal(t, data, buf.String())
+	})
+
+	t.Run("Request with typed params", func(t *testing.T) {
+		req := NewRequestWithID("test", []string{"a"}, int64(1))
+
+		var buf bytes.Buffer
+		_, err := req.WriteTo(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, `{"jsonrpc":"2.0","id":1,"method":"test","params":["a"]}`, buf.String())
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		var buf bytes.Buffer
+		n, err := NewRequestWithID("test", 42, int64(1)).WriteTo(&buf)
+		require.Error(t, err)
+		assert.Zero(t, n)
+		assert.Zero(t, buf.Len())
+	})
+
+	t.Run("Writer error", func(t *testing.T) {
+		req := NewRequestWithID("test", []any{1}, int64(1))
+		_, err := req.WriteTo(errWriter{})
+		assert.Error(t, err)
+	})
+}
+
+func TestRequest_ParamsEncodingCached(t *testing.T) {
+	req := NewRequestWithID("test", map[string]any{"a": 1}, int64(1))
+
+	first, err := req.MarshalJSON()
+	require.NoError(t, err)
+	cached := req.encodedParams
+	require.NotNil(t, cached)
+
+	second, err := req.MarshalJSON()
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Same(t, &cached[0], &req.encodedParams[0], "params should only be encoded once")
+}
+
+// errWriter is an io.Writer that always fails.
+type errWriter struct{}
+
+func (errWriter) Write(_ []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestRequest_Reset(t *testing.T) {
+	t.Run("Clears all fields", func(t *testing.T) {
+		req, err := DecodeRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"a","params":[1]}`))
+		require.NoError(t, err)
+		require.NotNil(t, req.Params())
+
+		req.Reset()
+		assert.Empty(t, req.JSONRPC)
+		assert.True(t, req.ID.IsAbsent())
+		assert.Empty(t, req.Method)
+		assert.Nil(t, req.Params())
+		assert.Nil(t, req.RawParams())
+		assert.Nil(t, req.encodedParams)
+	})
+
+	t.Run("Nil receiver", func(_ *testing.T) {
+		var req *Request
+		req.Reset()
+	})
+}
+
+func TestRequest_WithID(t *testing.T) {
+	t.Run("Decoded request", func(t *testing.T) {
+		req, err := DecodeRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"a","params":[1]}`))
+		require.NoError(t, err)
+
+		updated, err := req.WithID("next")
+		require.NoError(t, err)
+		assert.Equal(t, StringID("next"), updated.ID)
+		assert.Equal(t, Int64ID(1), req.ID)
+
+		data, err := updated.MarshalJSON()
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"jsonrpc":"2.0","id":"next","method":"a","params":[1]}`, string(data))
+	})
+
+	t.Run("Constructed request", func(t *testing.T) {
+		req := NewRequestWithID("a", map[string]any{"k": "v"}, 1)
+
+		updated, err := req.WithID(nil)
+		require.NoError(t, err)
+		assert.True(t, updated.IsNotification())
+		assert.JSONEq(t, `{"k":"v"}`, string(updated.RawParams()))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		var req *Request
+		_, err := req.WithID(1)
+		assert.Error(t, err)
+
+		_, err = NewRequest("a", nil).WithID(true)
+		assert.Error(t, err)
+
+		_, err = NewRequest("a", []any{make(chan int)}).WithID(1)
+		assert.Error(t, err)
+	})
+}
+
+func TestRequest_PeekParams(t *testing.T) {
+	t.Run("Positional params", func(t *testing.T) {
+		req, err := DecodeRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"eth_call",
+			"params":[{"to":"0x1","gas":21000,"value":1.5,"pending":true},"latest"]}`))
+		require.NoError(t, err)
+
+		tag, err := req.PeekParamStringByPath(1)
+		require.NoError(t, err)
+		assert.Equal(t, "latest", tag)
+
+		to, err := req.PeekParamStringByPath(0, "to")
+		require.NoError(t, err)
+		assert.Equal(t, "0x1", to)
+
+		gas, err := req.PeekParamInt64ByPath(0, "gas")
+		require.NoError(t, err)
+		assert.Equal(t, int64(21000), gas)
+
+		ugas, err := req.PeekParamUint64ByPath(0, "gas")
+		require.NoError(t, err)
+		assert.Equal(t, uint64(21000), ugas)
+
+		value, err := req.PeekParamFloat64ByPath(0, "value")
+		require.NoError(t, err)
+		assert.InDelta(t, 1.5, value, 0)
+
+		pending, err := req.PeekParamBoolByPath(0, "pending")
+		require.NoError(t, err)
+		assert.True(t, pending)
+
+		n, err := req.PeekParamLenByPath()
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+
+		raw, err := req.PeekParamBytesByPath(0)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"to":"0x1","gas":21000,"value":1.5,"pending":true}`, string(raw))
+
+		assert.True(t, req.PeekParamExists(0, "to"))
+		assert.False(t, req.PeekParamExists(2))
+	})
+
+	t.Run("Named params from Go values", func(t *testing.T) {
+		req := NewRequest("subscribe", map[string]any{"chainId": 137, "topics": []string{"a"}})
+
+		chainID, err := req.PeekParamInt64ByPath("chainId")
+		require.NoError(t, err)
+		assert.Equal(t, int64(137), chainID)
+
+		topic, err := req.PeekParamStringByPath("topics", 0)
+		require.NoError(t, err)
+		assert.Equal(t, "a", topic)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		req := NewRequestWithRawParams("m", []byte(`{"a":"x"}`))
+
+		_, err := req.PeekParamStringByPath("missing")
+		assert.ErrorContains(t, err, "path not found")
+		_, err = req.PeekParamInt64ByPath("a")
+		assert.ErrorContains(t, err, "not a number")
+		_, err = req.PeekParamLenByPath("a")
+		assert.Error(t, err)
+
+		_, err = NewRequest("m", nil).PeekParamStringByPath(0)
+		assert.ErrorContains(t, err, "no params field")
+		assert.False(t, NewRequest("m", nil).PeekParamExists())
+	})
+
+	t.Run("Cache is cleared when the request is reused", func(t *testing.T) {
+		req := AcquireRequest()
+		defer ReleaseRequest(req)
+
+		require.NoError(t, DecodeRequestInto(req,
+			[]byte(`{"jsonrpc":"2.0","id":1,"method":"m","params":["first"]}`)))
+		first, err := req.PeekParamStringByPath(0)
+		require.NoError(t, err)
+		assert.Equal(t, "first", first)
+
+		require.NoError(t, DecodeRequestInto(req,
+			[]byte(`{"jsonrpc":"2.0","id":2,"method":"m","params":["second"]}`)))
+		second, err := req.PeekParamStringByPath(0)
+		require.NoError(t, err)
+		assert.Equal(t, "second", second)
+	})
+}
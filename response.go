@@ -14,20 +14,19 @@ import (
 // Response is a struct for JSON-RPC responses conforming to the JSON-RPC 2.0 specification.
 // Response instances are immutable after decoding and safe for concurrent reads.
 //
-// The Response type uses lazy unmarshaling for the id and error fields to optimize performance.
+// The Response type uses lazy unmarshaling for the error field to optimize performance. The id is
+// kept in its exact raw encoding, so it is written back unchanged without re-marshaling.
 type Response struct {
 	// Immutable data fields
 	jsonrpc string
-	id      any
+	id      ID
 	err     *Error
 	result  json.RawMessage
 
-	// Internal fields for lazy unmarshaling and caching
-	rawID    json.RawMessage
+	// Internal field for lazy unmarshaling
 	rawError json.RawMessage
 
-	// One-time initialization guards for lazy operations
-	idOnce  sync.Once
+	// One-time initialization guard for lazy operations
 	errOnce sync.Once
 
 	// AST node caching for efficient field access
@@ -37,65 +36,46 @@ type Response struct {
 	astErr   error
 }
 
-// NewResponse creates a JSON-RPC 2.0 response with a result.
+// NewResponse creates a JSON-RPC 2.0 response with a result. The id can be an ID or any value
+// accepted by NewID, where nil results in a null id.
 func NewResponse(id any, result any) (*Response, error) {
 	resultBytes, err := getSonicAPI().Marshal(result)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal result: %w", err)
 	}
 
-	// Pre-marshal the ID to cache it for later use
-	var rawID json.RawMessage
-	if id != nil {
-		idBytes, err := getSonicAPI().Marshal(id)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal id: %w", err)
-		}
-		rawID = idBytes
+	respID, err := NewID(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id: %w", err)
 	}
 
 	return &Response{
 		jsonrpc: jsonRPCVersion,
-		id:      id,
-		rawID:   rawID,
+		id:      respID,
 		result:  resultBytes,
 	}, nil
 }
 
 // NewResponseFromRaw creates a JSON-RPC 2.0 response with a raw result.
 func NewResponseFromRaw(id any, rawResult json.RawMessage) (*Response, error) {
-	var rawID json.RawMessage
-	if id != nil {
-		idBytes, err := getSonicAPI().Marshal(id)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal id: %w", err)
-		}
-		rawID = idBytes
+	respID, err := NewID(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id: %w", err)
 	}
 
 	return &Response{
 		jsonrpc: jsonRPCVersion,
-		id:      id,
-		rawID:   rawID,
+		id:      respID,
 		result:  rawResult,
 	}, nil
 }
 
-// NewErrorResponse creates a JSON-RPC 2.0 error response.
+// NewErrorResponse creates a JSON-RPC 2.0 error response. Unsupported id values are reported by
+// Validate.
 func NewErrorResponse(id any, err *Error) *Response {
-	var rawID json.RawMessage
-	if id != nil {
-		idBytes, marshalErr := getSonicAPI().Marshal(id)
-		if marshalErr == nil {
-			rawID = idBytes
-		}
-		// If marshal fails, rawID remains nil and MarshalJSON will handle it
-	}
-
 	return &Response{
 		jsonrpc: jsonRPCVersion,
-		id:      id,
-		rawID:   rawID,
+		id:      toID(id),
 		err:     err,
 	}
 }
@@ -122,33 +102,26 @@ func (r *Response) RawResult() json.RawMessage {
 	return r.result
 }
 
-// IDOrNil returns the unmarshaled ID, or nil if unmarshaling fails.
-func (r *Response) IDOrNil() any {
-	r.idOnce.Do(func() {
-		// Ignore error - validation happens during decode
-		_ = r.unmarshalID()
-	})
+// ID returns the ID of the response, keeping its exact raw encoding.
+func (r *Response) ID() ID {
 	return r.id
 }
 
-// IDString returns the ID as a string.
+// IDOrNil returns the ID as a plain Go value, or nil for absent and null IDs. See ID.Value for the
+// conversion rules; use ID for exact access to large or fractional numbers.
+func (r *Response) IDOrNil() any {
+	return r.id.Value()
+}
+
+// IDString returns the ID as a string. See ID.String for details.
 func (r *Response) IDString() string {
-	id := r.IDOrNil()
-	switch id := id.(type) {
-	case string:
-		return id
-	case int64:
-		return fmt.Sprintf("%d", id)
-	case float64:
-		return formatFloat64ID(id)
-	default:
-		return ""
-	}
+	return r.id.String()
 }
 
 // String returns a string representation of the JSON-RPC response.
 func (r *Response) String() string {
-	return fmt.Sprintf("ID: %v, Error: %v, Result byte size: %d", r.id, r.err, len(r.result))
+	return fmt.Sprintf("ID: %s, Error: %v, Result byte size: %d",
+		formatIDForString(r.id), r.err, len(r.result))
 }
 
 // Validate checks if the Response conforms to the JSON-RPC specification.
@@ -161,15 +134,8 @@ func (r *Response) Validate() error {
 		return fmt.Errorf("invalid jsonrpc version: %s", r.jsonrpc)
 	}
 
-	// Normalize int to int64 for consistency
-	switch v := r.id.(type) {
-	case nil, string, int64, float64:
-		// Already in correct format
-	case int:
-		// Convert platform-dependent int to int64
-		r.id = int64(v)
-	default:
-		return errors.New("id field must be a string or a number")
+	if err := r.id.validate(); err != nil {
+		return err
 	}
 
 	if r.err != nil && r.result != nil || r.rawError != nil && r.result != nil {
@@ -191,11 +157,8 @@ func (r *Response) Equals(other *Response) bool {
 		return false
 	}
 
-	// Ensure both IDs are unmarshaled before comparing
-	rID := r.IDOrNil()
-	otherID := other.IDOrNil()
-
-	if rID != otherID {
+	// Absent and null IDs are both written as null, so they are considered equal
+	if r.id != other.id && !(isNullOrAbsentID(r.id) && isNullOrAbsentID(other.id)) {
 		return false
 	}
 
@@ -271,13 +234,27 @@ func isEmptyResult(result json.RawMessage) bool {
 
 // DecodeResponse parses and returns a new Response from a byte slice.
 func DecodeResponse(data []byte) (*Response, error) {
+	resp := &Response{}
+	if err := DecodeResponseInto(resp, data); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DecodeResponseInto parses a Response from a byte slice into an existing Response, which is reset
+// before decoding. This allows reusing Response objects, e.g. ones obtained from AcquireResponse.
+// If decoding fails, the contents of resp are unspecified until it is reset or decoded into again.
+func DecodeResponseInto(resp *Response, data []byte) error {
+	if resp == nil {
+		return errors.New("cannot decode into nil response")
+	}
 	if len(bytes.TrimSpace(data)) == 0 {
-		return nil, errors.New(errEmptyData)
+		return errors.New(errEmptyData)
 	}
 
-	resp := &Response{}
+	resp.Reset()
 	if err := resp.parseFromBytes(data); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	// If the response carries an error (and no result), decode it eagerly so callers
@@ -285,11 +262,11 @@ func DecodeResponse(data []byte) (*Response, error) {
 	if len(resp.result) == 0 && len(resp.rawError) > 0 {
 		resp.err = &Error{}
 		if err := resp.err.UnmarshalJSON(resp.rawError); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal JSON-RPC error: %w", err)
+			return fmt.Errorf("failed to unmarshal JSON-RPC error: %w", err)
 		}
 	}
 
-	return resp, nil
+	return nil
 }
 
 // DecodeResponseFromReader parses and returns a new Response from an io.Reader.
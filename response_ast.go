@@ -1,8 +1,11 @@
 package jsonrpc
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"iter"
+	"strconv"
 
 	"github.com/bytedance/sonic/ast"
 )
@@ -19,27 +22,12 @@ import (
 // The AST node is lazily built on first call and cached for subsequent calls, making repeated
 // field access very efficient.
 func (r *Response) PeekStringByPath(path ...any) (string, error) {
-	node, err := r.getASTNode()
+	node, err := r.peekNode(path...)
 	if err != nil {
 		return "", err
 	}
 
-	// Navigate to the requested path
-	if len(path) > 0 {
-		targetNode := node.GetByPath(path...)
-		if targetNode == nil || !targetNode.Valid() {
-			return "", errors.New("path not found")
-		}
-		node = *targetNode
-	}
-
-	// Extract string value
-	str, err := node.String()
-	if err != nil {
-		return "", fmt.Errorf("value at path is not a string: %w", err)
-	}
-
-	return str, nil
+	return nodeString(&node)
 }
 
 // PeekBytesByPath returns raw JSON bytes for a nested field without unmarshaling the entire result.
@@ -52,29 +40,583 @@ func (r *Response) PeekStringByPath(path ...any) (string, error) {
 // The AST node is lazily built on first call and cached for subsequent calls, making repeated
 // field access very efficient.
 func (r *Response) PeekBytesByPath(path ...any) ([]byte, error) {
-	node, err := r.getASTNode()
+	node, err := r.peekNode(path...)
 	if err != nil {
 		return nil, err
 	}
 
+	return nodeBytes(&node)
+}
+
+// PeekInt64ByPath extracts an integer field from the result without unmarshaling the entire
+// result. The value at the path must be a JSON number without fraction or exponent that fits in
+// an int64; strings holding numbers are rejected.
+//
+//	gasUsed, err := response.PeekInt64ByPath("receipt", "gasUsed")
+func (r *Response) PeekInt64ByPath(path ...any) (int64, error) {
+	node, err := r.peekNode(path...)
+	if err != nil {
+		return 0, err
+	}
+	return nodeInt64(&node)
+}
+
+// PeekUint64ByPath extracts an unsigned integer field from the result without unmarshaling the
+// entire result. The value at the path must be a non-negative JSON integer that fits in a uint64.
+func (r *Response) PeekUint64ByPath(path ...any) (uint64, error) {
+	node, err := r.peekNode(path...)
+	if err != nil {
+		return 0, err
+	}
+	return nodeUint64(&node)
+}
+
+// PeekFloat64ByPath extracts a numeric field from the result as a float64 without unmarshaling
+// the entire result. Numbers that cannot be represented exactly are rounded.
+func (r *Response) PeekFloat64ByPath(path ...any) (float64, error) {
+	node, err := r.peekNode(path...)
+	if err != nil {
+		return 0, err
+	}
+	return nodeFloat64(&node)
+}
+
+// PeekBoolByPath extracts a boolean field from the result without unmarshaling the entire result.
+func (r *Response) PeekBoolByPath(path ...any) (bool, error) {
+	node, err := r.peekNode(path...)
+	if err != nil {
+		return false, err
+	}
+	return nodeBool(&node)
+}
+
+// PeekLenByPath returns the number of elements of an array, or the number of members of an
+// object, at the given path without unmarshaling the entire result.
+//
+//	txCount, err := response.PeekLenByPath("transactions")
+func (r *Response) PeekLenByPath(path ...any) (int, error) {
+	node, err := r.peekNode(path...)
+	if err != nil {
+		return 0, err
+	}
+
+	return nodeLen(&node)
+}
+
+// PeekExists returns true if the result contains a value at the given path. A value that is
+// explicitly null exists.
+func (r *Response) PeekExists(path ...any) bool {
+	_, err := r.peekNode(path...)
+	return err == nil
+}
+
+// PeekElementsByPath returns an iterator over the elements of the array at the given path, yielding
+// the index and raw JSON bytes of each element. If the value at the path is an object, its member
+// values are yielded in document order instead. Use PeekMembersByPath to also get the keys.
+//
+//	seq, err := response.PeekElementsByPath("transactions")
+//	for i, tx := range seq {
+//		// Unmarshal tx
+//	}
+func (r *Response) PeekElementsByPath(path ...any) (iter.Seq2[int, []byte], error) {
+	node, err := r.peekContainer(path...)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(yield func(int, []byte) bool) {
+		_ = node.ForEach(func(seq ast.Sequence, child *ast.Node) bool {
+			raw, err := child.Raw()
+			if err != nil {
+				return false
+			}
+			return yield(seq.Index, []byte(raw))
+		})
+	}, nil
+}
+
+// PeekMembersByPath returns an iterator over the members of the object at the given path,
+// yielding the key and raw JSON bytes of each member in document order.
+//
+//	seq, err := response.PeekMembersByPath("balances")
+//	for address, balance := range seq {
+//		// Use address and balance
+//	}
+func (r *Response) PeekMembersByPath(path ...any) (iter.Seq2[string, []byte], error) {
+	node, err := r.peekContainer(path...)
+	if err != nil {
+		return nil, err
+	}
+	if node.TypeSafe() != ast.V_OBJECT {
+		return nil, errors.New("value at path is not an object")
+	}
+
+	return func(yield func(string, []byte) bool) {
+		_ = node.ForEach(func(seq ast.Sequence, child *ast.Node) bool {
+			raw, err := child.Raw()
+			if err != nil {
+				return false
+			}
+			return yield(*seq.Key, []byte(raw))
+		})
+	}, nil
+}
+
+// peekNode returns the AST node at the given path, or the root node if the path is empty.
+func (r *Response) peekNode(path ...any) (ast.Node, error) {
+	node, err := r.getASTNode()
+	if err != nil {
+		return ast.Node{}, err
+	}
+
 	// Navigate to the requested path
 	if len(path) > 0 {
 		targetNode := node.GetByPath(path...)
-		if targetNode == nil || !targetNode.Valid() {
-			return nil, errors.New("path not found")
+		if targetNode == nil || !targetNode.Exists() {
+			return ast.Node{}, errors.New("path not found")
 		}
 		node = *targetNode
 	}
 
-	// Get raw JSON bytes
+	return node, nil
+}
+
+// peekContainer returns the array or object node at the given path.
+func (r *Response) peekContainer(path ...any) (ast.Node, error) {
+	node, err := r.peekNode(path...)
+	if err != nil {
+		return ast.Node{}, err
+	}
+
+	switch node.TypeSafe() {
+	case ast.V_ARRAY, ast.V_OBJECT:
+		return node, nil
+	default:
+		return ast.Node{}, errors.New("value at path is not an array or object")
+	}
+}
+
+// Query evaluates a compiled path against the result and returns the raw JSON bytes of all
+// selected values in document order. A path that selects nothing returns an empty slice and no
+// error. The cached AST node is shared with the Peek accessors.
+//
+//	hashes := jsonrpc.MustCompilePath("transactions[*].hash")
+//	values, err := response.Query(hashes)
+func (r *Response) Query(p *Path) ([][]byte, error) {
+	nodes, err := r.queryNodes(p)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([][]byte, 0, len(nodes))
+	for i := range nodes {
+		raw, err := nodeBytes(&nodes[i])
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, raw)
+	}
+	return values, nil
+}
+
+// QueryFirst returns the raw JSON bytes of the first value selected by the path. Returns an error
+// if the path selects nothing.
+func (r *Response) QueryFirst(p *Path) ([]byte, error) {
+	node, err := r.queryFirstNode(p)
+	if err != nil {
+		return nil, err
+	}
+	return nodeBytes(&node)
+}
+
+// QueryString returns the first value selected by the path as a string, with the same conversion
+// rules as PeekStringByPath.
+func (r *Response) QueryString(p *Path) (string, error) {
+	node, err := r.queryFirstNode(p)
+	if err != nil {
+		return "", err
+	}
+	return nodeString(&node)
+}
+
+// QueryInt64 returns the first value selected by the path as an int64, with the same conversion
+// rules as PeekInt64ByPath.
+func (r *Response) QueryInt64(p *Path) (int64, error) {
+	node, err := r.queryFirstNode(p)
+	if err != nil {
+		return 0, err
+	}
+	return nodeInt64(&node)
+}
+
+// QueryUint64 returns the first value selected by the path as a uint64, with the same conversion
+// rules as PeekUint64ByPath.
+func (r *Response) QueryUint64(p *Path) (uint64, error) {
+	node, err := r.queryFirstNode(p)
+	if err != nil {
+		return 0, err
+	}
+	return nodeUint64(&node)
+}
+
+// QueryFloat64 returns the first value selected by the path as a float64.
+func (r *Response) QueryFloat64(p *Path) (float64, error) {
+	node, err := r.queryFirstNode(p)
+	if err != nil {
+		return 0, err
+	}
+	return nodeFloat64(&node)
+}
+
+// QueryBool returns the first value selected by the path as a bool.
+func (r *Response) QueryBool(p *Path) (bool, error) {
+	node, err := r.queryFirstNode(p)
+	if err != nil {
+		return false, err
+	}
+	return nodeBool(&node)
+}
+
+// queryNodes evaluates the path against the cached AST node.
+func (r *Response) queryNodes(p *Path) ([]ast.Node, error) {
+	if p == nil {
+		return nil, errors.New("path cannot be nil")
+	}
+
+	root, err := r.getASTNode()
+	if err != nil {
+		return nil, err
+	}
+	return p.eval(root), nil
+}
+
+// queryFirstNode returns the first node selected by the path.
+func (r *Response) queryFirstNode(p *Path) (ast.Node, error) {
+	nodes, err := r.queryNodes(p)
+	if err != nil {
+		return ast.Node{}, err
+	}
+	if len(nodes) == 0 {
+		return ast.Node{}, errors.New("path not found")
+	}
+	return nodes[0], nil
+}
+
+// nodeBytes returns the raw JSON bytes of node.
+func nodeBytes(node *ast.Node) ([]byte, error) {
 	raw, err := node.Raw()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get raw bytes: %w", err)
 	}
-
 	return []byte(raw), nil
 }
 
+// nodeString returns the string value of node. Numbers are converted to their string form.
+func nodeString(node *ast.Node) (string, error) {
+	str, err := node.String()
+	if err != nil {
+		return "", fmt.Errorf("value at path is not a string: %w", err)
+	}
+	return str, nil
+}
+
+// nodeInt64 returns the value of an integer node.
+func nodeInt64(node *ast.Node) (int64, error) {
+	raw, err := nodeNumber(node)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.ParseInt(raw, decimalBase, 64)
+	if err != nil {
+		return 0, fmt.Errorf("value at path is not an int64: %w", err)
+	}
+	return n, nil
+}
+
+// nodeUint64 returns the value of a non-negative integer node.
+func nodeUint64(node *ast.Node) (uint64, error) {
+	raw, err := nodeNumber(node)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := strconv.ParseUint(raw, decimalBase, 64)
+	if err != nil {
+		return 0, fmt.Errorf("value at path is not a uint64: %w", err)
+	}
+	return n, nil
+}
+
+// nodeFloat64 returns the value of a number node as a float64.
+func nodeFloat64(node *ast.Node) (float64, error) {
+	raw, err := nodeNumber(node)
+	if err != nil {
+		return 0, err
+	}
+
+	f, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, fmt.Errorf("value at path is not a float64: %w", err)
+	}
+	return f, nil
+}
+
+// nodeBool returns the value of a boolean node.
+func nodeBool(node *ast.Node) (bool, error) {
+	switch node.TypeSafe() {
+	case ast.V_TRUE:
+		return true, nil
+	case ast.V_FALSE:
+		return false, nil
+	default:
+		return false, errors.New("value at path is not a boolean")
+	}
+}
+
+// nodeLen returns the number of elements or members of an array or object node.
+func nodeLen(node *ast.Node) (int, error) {
+	switch node.TypeSafe() {
+	case ast.V_ARRAY, ast.V_OBJECT:
+	default:
+		return 0, errors.New("value at path is not an array or object")
+	}
+
+	n, err := loadedLen(node)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load value at path: %w", err)
+	}
+	return n, nil
+}
+
+// loadedLen returns the number of elements or members of node after loading all of them, as the
+// length of a lazily parsed node only counts parsed children.
+func loadedLen(node *ast.Node) (int, error) {
+	if err := node.Load(); err != nil {
+		return 0, err
+	}
+	return node.Len()
+}
+
+// nodeNumber returns the raw text of a number node.
+func nodeNumber(node *ast.Node) (string, error) {
+	if node.TypeSafe() != ast.V_NUMBER {
+		return "", errors.New("value at path is not a number")
+	}
+
+	raw, err := node.Raw()
+	if err != nil {
+		return "", fmt.Errorf("failed to get raw bytes: %w", err)
+	}
+	return raw, nil
+}
+
+// SetByPath returns a copy of the response with the value at the given path of the result
+// replaced by value, leaving the original response untouched. The path is a sequence of object
+// keys and array indices as for PeekStringByPath; negative indices count from the end. Missing
+// object members along the path are created, while array indices must exist. An empty path
+// replaces the whole result.
+//
+// The value is encoded to JSON, except for json.RawMessage values, which are used as-is. Only the
+// parts of the result on the path are rebuilt, the rest is copied as raw JSON. The copy is created
+// with Clone, so the id is kept as is.
+//
+//	rewritten, err := response.SetByPath("0x1a", "block", "number")
+func (r *Response) SetByPath(value any, path ...any) (*Response, error) {
+	segments, err := keyPathSegments(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var valueNode ast.Node
+	if raw, ok := value.(json.RawMessage); ok {
+		valueNode = ast.NewRaw(string(raw))
+	} else {
+		encoded, err := getSonicAPI().Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal value: %w", err)
+		}
+		valueNode = ast.NewRaw(string(encoded))
+	}
+	if err := valueNode.Check(); err != nil {
+		return nil, fmt.Errorf("invalid value: %w", err)
+	}
+
+	return r.rewriteResult(func(root *ast.Node) (ast.Node, error) {
+		return setNode(root, segments, valueNode)
+	})
+}
+
+// DeleteByPath returns a copy of the response with the object member or array element at the given
+// path of the result removed, leaving the original response untouched. Returns an error if the
+// path does not exist.
+//
+//	stripped, err := response.DeleteByPath("vendorExtras")
+func (r *Response) DeleteByPath(path ...any) (*Response, error) {
+	segments, err := keyPathSegments(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) == 0 {
+		return nil, errors.New("cannot delete the whole result")
+	}
+
+	return r.rewriteResult(func(root *ast.Node) (ast.Node, error) {
+		return deleteNode(root, segments)
+	})
+}
+
+// rewriteResult returns a clone of the response with the result replaced by the re-serialized
+// output of rewrite.
+func (r *Response) rewriteResult(rewrite func(*ast.Node) (ast.Node, error)) (*Response, error) {
+	if r == nil {
+		return nil, errors.New("cannot rewrite nil response")
+	}
+	if len(r.result) == 0 {
+		return nil, errors.New("response has no result field")
+	}
+
+	root := ast.NewRaw(string(r.result))
+	rewritten, err := rewrite(&root)
+	if err != nil {
+		return nil, err
+	}
+	result, err := rewritten.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+
+	clone, err := r.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone response: %w", err)
+	}
+	clone.result = result
+
+	return clone, nil
+}
+
+// setNode returns node with the value at the path replaced by value. A missing node along the path
+// is created as an empty object.
+func setNode(node *ast.Node, segments []pathSegment, value ast.Node) (ast.Node, error) {
+	if len(segments) == 0 {
+		return value, nil
+	}
+
+	seg := segments[0]
+	if seg.kind == segmentKey && !node.Exists() {
+		child := ast.Node{}
+		out, err := setNode(&child, segments[1:], value)
+		if err != nil {
+			return ast.Node{}, err
+		}
+		return ast.NewObject([]ast.Pair{ast.NewPair(seg.key, out)}), nil
+	}
+
+	found := false
+	rebuilt, err := rebuildChildren(node, seg, func(child *ast.Node) (ast.Node, bool, error) {
+		found = true
+		out, err := setNode(child, segments[1:], value)
+		return out, true, err
+	})
+	if err != nil {
+		return ast.Node{}, err
+	}
+	if found {
+		return rebuilt, nil
+	}
+
+	// Append a missing object member
+	if seg.kind == segmentKey {
+		child := ast.Node{}
+		out, err := setNode(&child, segments[1:], value)
+		if err != nil {
+			return ast.Node{}, err
+		}
+		if _, err := rebuilt.Set(seg.key, out); err != nil {
+			return ast.Node{}, fmt.Errorf("failed to set %q: %w", seg.key, err)
+		}
+		return rebuilt, nil
+	}
+
+	return ast.Node{}, errors.New("path not found")
+}
+
+// deleteNode returns node with the value at the path removed.
+func deleteNode(node *ast.Node, segments []pathSegment) (ast.Node, error) {
+	found := false
+	rebuilt, err := rebuildChildren(node, segments[0], func(child *ast.Node) (ast.Node, bool, error) {
+		found = true
+		if len(segments) == 1 {
+			return ast.Node{}, false, nil
+		}
+		out, err := deleteNode(child, segments[1:])
+		return out, true, err
+	})
+	if err != nil {
+		return ast.Node{}, err
+	}
+	if !found {
+		return ast.Node{}, errors.New("path not found")
+	}
+	return rebuilt, nil
+}
+
+// rebuildChildren rebuilds the array or object node, replacing the child selected by seg with the
+// output of transform, or dropping it if transform returns false. Other children are copied as-is.
+// Returns an error if node is not of the type required by seg.
+func rebuildChildren(
+	node *ast.Node,
+	seg pathSegment,
+	transform func(*ast.Node) (ast.Node, bool, error),
+) (ast.Node, error) {
+	typ := node.TypeSafe()
+	switch {
+	case seg.kind == segmentKey && typ != ast.V_OBJECT:
+		return ast.Node{}, fmt.Errorf("cannot access key %q of a non-object value", seg.key)
+	case seg.kind == segmentIndex && typ != ast.V_ARRAY:
+		return ast.Node{}, fmt.Errorf("cannot access index %d of a non-array value", seg.index)
+	}
+
+	length := 0
+	if typ == ast.V_ARRAY {
+		var err error
+		if length, err = loadedLen(node); err != nil {
+			return ast.Node{}, err
+		}
+	}
+
+	var elems []ast.Node
+	var pairs []ast.Pair
+	var transformErr error
+	_ = node.ForEach(func(seq ast.Sequence, child *ast.Node) bool {
+		out, kept := *child, true
+		if seg.selects(seq, length, child) {
+			out, kept, transformErr = transform(child)
+			if transformErr != nil {
+				return false
+			}
+		}
+		if !kept {
+			return true
+		}
+
+		if seq.Key != nil {
+			pairs = append(pairs, ast.NewPair(*seq.Key, out))
+		} else {
+			elems = append(elems, out)
+		}
+		return true
+	})
+	if transformErr != nil {
+		return ast.Node{}, transformErr
+	}
+
+	if typ == ast.V_ARRAY {
+		return ast.NewArray(elems), nil
+	}
+	return ast.NewObject(pairs), nil
+}
+
 // buildASTNode lazily builds the AST node for the result field.
 func (r *Response) buildASTNode() {
 	if len(r.result) == 0 {
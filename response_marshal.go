@@ -25,23 +25,15 @@ type responseMarshalFormat struct {
 
 // MarshalJSON serializes the Response into a JSON-RPC 2.0 compliant byte slice.
 //
-// The method prioritizes parsed fields (id, err) over their raw counterparts (rawID, rawError)
-// when both are present. If only raw fields exist, they are used directly to avoid unnecessary
-// re-marshaling.
+// The id is written from its raw encoding. The parsed error is prioritized over the raw error when
+// both are present.
 func (r *Response) MarshalJSON() ([]byte, error) {
 	err := r.Validate()
 	if err != nil {
 		return nil, err
 	}
 
-	var id any
-	if len(r.rawID) > 0 {
-		id = r.rawID
-	} else if r.id != nil {
-		id = r.id
-	} else {
-		id = nil
-	}
+	id := json.RawMessage(r.id.appendJSON(nil))
 
 	if len(r.rawError) > 0 && r.err == nil {
 		r.err = &Error{}
@@ -107,11 +99,7 @@ func (r *Response) WriteTo(w io.Writer) (n int64, err error) {
 		return total, err
 	}
 
-	idBytes, err := r.getIDBytes()
-	if err != nil {
-		return total, err
-	}
-	if err = writeBytes(w, idBytes, &total); err != nil {
+	if err = writeString(w, r.id.rawOrNull(), &total); err != nil {
 		return total, err
 	}
 
@@ -132,6 +120,32 @@ func (r *Response) WriteTo(w io.Writer) (n int64, err error) {
 	return total, nil
 }
 
+// AppendJSON appends the JSON encoding of the Response to dst and returns the extended buffer. This
+// allows serializing into caller-owned, e.g. pooled, buffers without intermediate allocations.
+// If the response is invalid, dst is returned unchanged along with the error.
+func (r *Response) AppendJSON(dst []byte) ([]byte, error) {
+	if err := r.Validate(); err != nil {
+		return dst, err
+	}
+
+	out := append(dst, `{"jsonrpc":"2.0","id":`...)
+	out = r.id.appendJSON(out)
+
+	if r.err != nil || len(r.rawError) > 0 {
+		errorBytes, err := r.getErrorBytes()
+		if err != nil {
+			return dst, err
+		}
+		out = append(out, `,"error":`...)
+		out = append(out, errorBytes...)
+	} else {
+		out = append(out, `,"result":`...)
+		out = append(out, r.result...)
+	}
+
+	return append(out, '}'), nil
+}
+
 // UnmarshalResult deserializes the raw Result field into the provided destination.
 func (r *Response) UnmarshalResult(dst any) error {
 	if dst == nil {
@@ -201,11 +215,12 @@ func (r *Response) parseFromBytes(data []byte) error {
 		return errors.New("response must not contain both result and error")
 	}
 
-	// Parse and unmarshal the ID field
-	r.rawID = aux.ID
-	if err := r.unmarshalID(); err != nil {
+	// Parse the ID field, keeping its raw encoding
+	id, err := ParseID(aux.ID)
+	if err != nil {
 		return fmt.Errorf("failed to unmarshal ID: %w", err)
 	}
+	r.id = id
 
 	// Assign result or error accordingly
 	if aux.Result != nil {
@@ -233,46 +248,6 @@ func (r *Response) parseFromReader(reader io.Reader, expectedSize int) error {
 	return r.parseFromBytes(data)
 }
 
-// unmarshalID unmarshals the raw ID bytes into the ID field.
-// This function is designed to be called via sync.Once to ensure it runs exactly once.
-func (r *Response) unmarshalID() error {
-	// If there's no rawID to unmarshal, leave ID field as-is (may be nil or already set)
-	if len(r.rawID) == 0 {
-		return nil
-	}
-
-	var id any
-	if err := getSonicAPI().Unmarshal(r.rawID, &id); err != nil {
-		return fmt.Errorf("invalid id field: %w", err)
-	}
-
-	// If the value is "null", id will be nil
-	if id == nil {
-		r.id = nil
-		return nil
-	}
-
-	switch v := id.(type) {
-	case float64:
-		// JSON numbers are unmarshalled as float64, so an explicit integer check is needed
-		if v != float64(int64(v)) {
-			r.id = v
-		} else {
-			r.id = int64(v)
-		}
-	case string:
-		if v == "" {
-			r.id = nil
-		} else {
-			r.id = v
-		}
-	default:
-		return errors.New("id field must be a string or a number")
-	}
-
-	return nil
-}
-
 // writeString writes a string to the writer and updates the total byte count
 func writeString(w io.Writer, s string, total *int64) error {
 	return writeBytes(w, []byte(s), total)
@@ -285,17 +260,6 @@ func writeBytes(w io.Writer, b []byte, total *int64) error {
 	return err
 }
 
-// getIDBytes returns the marshaled ID bytes. Uses cached rawID if available to avoid re-marshaling.
-func (r *Response) getIDBytes() ([]byte, error) {
-	if len(r.rawID) > 0 {
-		return r.rawID, nil
-	}
-	if r.id != nil {
-		return getSonicAPI().Marshal(r.id)
-	}
-	return []byte("null"), nil
-}
-
 // writeErrorField writes the error field to the writer
 func (r *Response) writeErrorField(w io.Writer, total *int64) error {
 	if err := writeString(w, `,"error":`, total); err != nil {
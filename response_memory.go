@@ -14,7 +14,6 @@ const (
 	jsonStructureOverhead  = 35 // {"jsonrpc":"2.0","id":,"result":}
 	errorStructureOverhead = 20 // {"code":,"message":""}
 	errorDataEstimate      = 50 // rough estimate for error data field
-	float64SizeEstimate    = 12 // float64 average size (range: 5-23 bytes)
 	nullSize               = 4  // "null"
 	decimalBase            = 10 // base 10 for digit counting
 )
@@ -22,11 +21,13 @@ const (
 // Clone creates a copy of the response with minimal shared state.
 //
 // Deep copies:
-//   - rawID, rawError, result byte slices
+//   - rawError, result byte slices
 //   - Error struct
 //
+// Copies by value:
+//   - id field (ID is immutable)
+//
 // Shallow copies type any fields:
-//   - id field
 //   - Error.Data field
 //
 // Not copied:
@@ -41,15 +42,9 @@ func (r *Response) Clone() (*Response, error) {
 		jsonrpc: r.jsonrpc,
 	}
 
-	// Shallow copy ID (safe for primitives, pointers will be shared)
+	// ID is an immutable value, so copying it is safe
 	clone.id = r.id
 
-	// Deep copy rawID byte slice
-	if len(r.rawID) > 0 {
-		clone.rawID = make(json.RawMessage, len(r.rawID))
-		copy(clone.rawID, r.rawID)
-	}
-
 	// Copy Error
 	if r.err != nil {
 		clone.err = &Error{
@@ -75,7 +70,8 @@ func (r *Response) Clone() (*Response, error) {
 }
 
 // WithID returns a cloned response with the supplied ID while leaving the original untouched.
-// The clone keeps cached payload slices so callers avoid repeated marshaling work.
+// The clone keeps cached payload slices so callers avoid repeated marshaling work. The id can be
+// an ID or any value accepted by NewID, where nil results in a null id.
 func (r *Response) WithID(newID any) (*Response, error) {
 	if r == nil {
 		return nil, errors.New("cannot update id on nil response")
@@ -86,18 +82,12 @@ func (r *Response) WithID(newID any) (*Response, error) {
 		return nil, fmt.Errorf("failed to clone response: %w", err)
 	}
 
-	var rawID json.RawMessage
-	if newID != nil {
-		rawBytes, err := getSonicAPI().Marshal(newID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal id: %w", err)
-		}
-		rawID = rawBytes
+	id, err := NewID(newID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id: %w", err)
 	}
 
-	clone.id = newID
-	clone.rawID = rawID
-	clone.idOnce = sync.Once{}
+	clone.id = id
 	clone.errOnce = sync.Once{}
 
 	if err := clone.Validate(); err != nil {
@@ -113,7 +103,6 @@ func (r *Response) Free() {
 		return
 	}
 
-	r.rawID = nil
 	r.rawError = nil
 	r.result = nil
 
@@ -125,6 +114,30 @@ func (r *Response) Free() {
 	// Note: we keep r.id and r.err for logging purposes (typically small values)
 }
 
+// Reset clears all fields of the Response, including lazily unmarshaled values and the AST cache,
+// so that it can be reused. Unlike Free, which keeps the id and error for logging, Reset returns
+// the Response to its zero state.
+//
+// Reset must not be called while the Response is in use by other goroutines.
+func (r *Response) Reset() {
+	if r == nil {
+		return
+	}
+
+	r.jsonrpc = ""
+	r.id = ID{}
+	r.err = nil
+	r.result = nil
+	r.rawError = nil
+	r.errOnce = sync.Once{}
+
+	r.astMutex.Lock()
+	r.astNode = ast.Node{}
+	r.astErr = nil
+	r.astMutex.Unlock()
+	r.astOnce = sync.Once{}
+}
+
 // Size returns the approximate serialized size of the response in bytes.
 func (r *Response) Size() int {
 	if r == nil {
@@ -139,24 +152,12 @@ func (r *Response) Size() int {
 	return size
 }
 
-// idSize estimates the size of the ID field
+// idSize returns the size of the ID field
 func (r *Response) idSize() int {
-	if r.id != nil {
-		switch v := r.id.(type) {
-		case string:
-			return len(v) + 2 // +2 for quotes
-		case int64:
-			return intDigits(int(v))
-		case float64:
-			return float64SizeEstimate
-		default:
-			return nullSize
-		}
-	}
-	if len(r.rawID) > 0 {
-		return len(r.rawID)
+	if r.id.raw == "" {
+		return nullSize
 	}
-	return nullSize
+	return len(r.id.raw)
 }
 
 // errorSize estimates the size of the error field
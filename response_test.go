@@ -67,14 +67,14 @@ func TestResponse_Equals(t *testing.T) {
 		},
 		{
 			name:      "Different id: s",
-			this:      &Response{id: "id1"},
-			other:     &Response{id: "id2"},
+			this:      &Response{id: StringID("id1")},
+			other:     &Response{id: StringID("id2")},
 			assertion: false,
 		},
 		{
 			name:      "Different ID types",
-			this:      &Response{id: "some-id"},
-			other:     &Response{id: 24},
+			this:      &Response{id: StringID("some-id")},
+			other:     &Response{id: Int64ID(24)},
 			assertion: false,
 		},
 		{
@@ -156,14 +156,14 @@ func TestResponse_EqualsMixedLazyEager(t *testing.T) {
 		eager, err := DecodeResponse(data)
 		require.NoError(t, err)
 
-		// Create another response with raw ID still unparsed
+		// Create another response directly from an ID value
 		lazy := &Response{
 			jsonrpc: "2.0",
-			rawID:   json.RawMessage(`42`),
+			id:      Int64ID(42),
 			result:  json.RawMessage(`"success"`),
 		}
 
-		// They should be equal even though one has ID unmarshaled and other doesn't
+		// They should be equal even though one ID was decoded and the other constructed
 		assert.True(t, eager.Equals(lazy))
 		assert.True(t, lazy.Equals(eager))
 	})
@@ -174,10 +174,10 @@ func TestResponse_EqualsMixedLazyEager(t *testing.T) {
 		eager, err := DecodeResponse(data)
 		require.NoError(t, err)
 
-		// Create another response with different raw ID
+		// Create another response with a different ID
 		lazy := &Response{
 			jsonrpc: "2.0",
-			rawID:   json.RawMessage(`99`),
+			id:      Int64ID(99),
 			result:  json.RawMessage(`"success"`),
 		}
 
@@ -195,7 +195,7 @@ func TestResponse_EqualsMixedLazyEager(t *testing.T) {
 		// Create another response with raw error still unparsed
 		lazy := &Response{
 			jsonrpc:  "2.0",
-			rawID:    json.RawMessage(`1`),
+			id:       Int64ID(1),
 			rawError: json.RawMessage(`{"code":-32000,"message":"test error"}`),
 		}
 
@@ -213,7 +213,7 @@ func TestResponse_EqualsMixedLazyEager(t *testing.T) {
 		// Create another response with different raw error
 		lazy := &Response{
 			jsonrpc:  "2.0",
-			rawID:    json.RawMessage(`1`),
+			id:       Int64ID(1),
 			rawError: json.RawMessage(`{"code":-32001,"message":"different error"}`),
 		}
 
@@ -225,13 +225,13 @@ func TestResponse_EqualsMixedLazyEager(t *testing.T) {
 	t.Run("Both lazy - same values", func(t *testing.T) {
 		lazy1 := &Response{
 			jsonrpc: "2.0",
-			rawID:   json.RawMessage(`"test-id"`),
+			id:      StringID("test-id"),
 			result:  json.RawMessage(`"result"`),
 		}
 
 		lazy2 := &Response{
 			jsonrpc: "2.0",
-			rawID:   json.RawMessage(`"test-id"`),
+			id:      StringID("test-id"),
 			result:  json.RawMessage(`"result"`),
 		}
 
@@ -241,6 +241,51 @@ func TestResponse_EqualsMixedLazyEager(t *testing.T) {
 	})
 }
 
+func TestResponse_IDRoundTrip(t *testing.T) {
+	cases := []string{
+		`{"jsonrpc":"2.0","id":18446744073709551616,"result":1}`,
+		`{"jsonrpc":"2.0","id":9007199254740993,"result":1}`,
+		`{"jsonrpc":"2.0","id":1.0,"result":1}`,
+		`{"jsonrpc":"2.0","id":"","result":1}`,
+		`{"jsonrpc":"2.0","id":null,"result":1}`,
+	}
+
+	for _, data := range cases {
+		resp, err := DecodeResponse([]byte(data))
+		require.NoError(t, err)
+
+		marshaled, err := resp.MarshalJSON()
+		require.NoError(t, err)
+		assert.Equal(t, data, string(marshaled))
+
+		var buf bytes.Buffer
+		_, err = resp.WriteTo(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, data, buf.String())
+	}
+
+	t.Run("Exact integer access", func(t *testing.T) {
+		resp, err := DecodeResponse([]byte(`{"jsonrpc":"2.0","id":9007199254740993,"result":1}`))
+		require.NoError(t, err)
+
+		n, err := resp.ID().Int64()
+		require.NoError(t, err)
+		assert.Equal(t, int64(9007199254740993), n)
+		assert.Equal(t, "9007199254740993", resp.IDString())
+	})
+
+	t.Run("Null and absent ids", func(t *testing.T) {
+		withNull, err := DecodeResponse([]byte(`{"jsonrpc":"2.0","id":null,"result":1}`))
+		require.NoError(t, err)
+		withoutID, err := DecodeResponse([]byte(`{"jsonrpc":"2.0","result":1}`))
+		require.NoError(t, err)
+
+		assert.True(t, withNull.ID().IsNull())
+		assert.True(t, withoutID.ID().IsAbsent())
+		assert.True(t, withNull.Equals(withoutID), "both are written as null")
+	})
+}
+
 func TestResponse_IDString(t *testing.T) {
 	t.Run("No ID set => returns empty string", func(t *testing.T) {
 		resp := &Response{}
@@ -248,37 +293,37 @@ func TestResponse_IDString(t *testing.T) {
 	})
 
 	t.Run("ID is string text", func(t *testing.T) {
-		resp := &Response{id: "my-unique-id"}
+		resp := &Response{id: StringID("my-unique-id")}
 		assert.Equal(t, "my-unique-id", resp.IDString())
 	})
 
 	t.Run("ID is string integer", func(t *testing.T) {
-		resp := &Response{id: "15"}
+		resp := &Response{id: StringID("15")}
 		assert.Equal(t, "15", resp.IDString())
 	})
 
 	t.Run("ID is string float", func(t *testing.T) {
-		resp := &Response{id: "33.75"}
+		resp := &Response{id: StringID("33.75")}
 		assert.Equal(t, "33.75", resp.IDString())
 	})
 
 	t.Run("ID is int64", func(t *testing.T) {
-		resp := &Response{id: int64(12345)}
+		resp := &Response{id: Int64ID(12345)}
 		assert.Equal(t, "12345", resp.IDString())
 	})
 
 	t.Run("ID is float64", func(t *testing.T) {
-		resp := &Response{id: float64(12345.67)}
+		resp := &Response{id: Float64ID(12345.67)}
 		assert.Equal(t, "12345.67", resp.IDString())
 	})
 
 	t.Run("ID is float64 integer value", func(t *testing.T) {
-		resp := &Response{id: float64(25.0)}
+		resp := &Response{id: Float64ID(25.0)}
 		assert.Equal(t, "25.0", resp.IDString())
 	})
 
 	t.Run("ID is other type => returns empty string", func(t *testing.T) {
-		resp := &Response{id: []int{1, 2, 3}}
+		resp := &Response{id: toID([]int{1, 2, 3})}
 		assert.Equal(t, "", resp.IDString())
 	})
 }
@@ -406,7 +451,7 @@ func TestResponse_MarshalJSON(t *testing.T) {
 			name: "Response with result",
 			resp: &Response{
 				jsonrpc: "2.0",
-				id:      int64(1),
+				id:      Int64ID(1),
 				result:  []byte(`{"foo":"bar"}`),
 			},
 			json: []byte(`{"jsonrpc":"2.0","id":1,"result":{"foo":"bar"}}`),
@@ -415,7 +460,7 @@ func TestResponse_MarshalJSON(t *testing.T) {
 			name: "Response with Error",
 			resp: &Response{
 				jsonrpc: "2.0",
-				id:      "first",
+				id:      StringID("first"),
 				err:     &Error{Code: 123, Message: "test msg"},
 			},
 			json: []byte(
@@ -426,7 +471,7 @@ func TestResponse_MarshalJSON(t *testing.T) {
 			name: "Response with rawError and nil ID",
 			resp: &Response{
 				jsonrpc:  "2.0",
-				id:       nil,
+				id:       ID{},
 				rawError: []byte(`{"code":123,"message":"test msg"}`),
 			},
 			json: []byte(
@@ -437,7 +482,7 @@ func TestResponse_MarshalJSON(t *testing.T) {
 			name: "Invalid: both result and error",
 			resp: &Response{
 				jsonrpc: "2.0",
-				id:      "first",
+				id:      StringID("first"),
 				result:  []byte(`{"foo":"bar"}`),
 				err:     &Error{Code: 123, Message: "test msg"},
 			},
@@ -540,7 +585,7 @@ func TestResponse_parseFromBytes(t *testing.T) {
 		resp := &Response{}
 		err := resp.parseFromBytes(raw)
 		require.NoError(t, err)
-		assert.NotNil(t, resp.rawID)
+		assert.Equal(t, Int64ID(1), resp.id)
 		assert.NotNil(t, resp.RawResult())
 		assert.Nil(t, resp.Err())
 		assert.Nil(t, resp.rawError)
@@ -551,7 +596,7 @@ func TestResponse_parseFromBytes(t *testing.T) {
 		resp := &Response{}
 		err := resp.parseFromBytes(raw)
 		require.NoError(t, err)
-		assert.NotNil(t, resp.rawID)
+		assert.Equal(t, Int64ID(1), resp.id)
 		assert.Nil(t, resp.RawResult())
 		// Err() triggers lazy unmarshaling, so it should return the error
 		assert.NotNil(t, resp.Err())
@@ -564,7 +609,7 @@ func TestResponse_parseFromBytes(t *testing.T) {
 		resp := &Response{}
 		err := resp.parseFromBytes(raw)
 		require.NoError(t, err)
-		assert.NotNil(t, resp.rawID)
+		assert.Equal(t, Int64ID(1), resp.id)
 		assert.NotNil(t, resp.RawResult())
 		assert.Nil(t, resp.Err())
 		assert.Nil(t, resp.rawError)
@@ -576,7 +621,7 @@ func TestResponse_parseFromBytes(t *testing.T) {
 		err := resp.parseFromBytes(raw)
 		require.Error(t, err)
 		assert.Contains(t, err.Error(), "response must not contain both result and error")
-		assert.Nil(t, resp.rawID)
+		assert.True(t, resp.id.IsAbsent())
 		assert.Nil(t, resp.RawResult())
 		assert.Nil(t, resp.Err())
 		assert.Nil(t, resp.rawError)
@@ -595,7 +640,7 @@ func TestResponse_parseFromBytes(t *testing.T) {
 			resp := &Response{}
 			err := resp.parseFromBytes(tc)
 			require.Error(t, err)
-			assert.Nil(t, resp.rawID)
+			assert.True(t, resp.id.IsAbsent())
 			assert.Nil(t, resp.rawError)
 			assert.Nil(t, resp.Err())
 			assert.Nil(t, resp.RawResult())
@@ -734,7 +779,7 @@ func TestResponse_Validate(t *testing.T) {
 			name: "Valid response with result",
 			resp: &Response{
 				jsonrpc: "2.0",
-				id:      int64(1),
+				id:      Int64ID(1),
 				result:  []byte(`{"foo":"bar"}`),
 			},
 			runtimeErr: false,
@@ -743,14 +788,14 @@ func TestResponse_Validate(t *testing.T) {
 			name: "Valid response with error",
 			resp: &Response{
 				jsonrpc: "2.0",
-				id:      "first",
+				id:      StringID("first"),
 				err:     &Error{Code: 123, Message: "test msg"},
 			},
 			runtimeErr: false,
 		},
 		{
 			name:       "Invalid JSON-RPC version",
-			resp:       &Response{jsonrpc: "1.0", id: int64(1), result: []byte(`{"foo":"bar"}`)},
+			resp:       &Response{jsonrpc: "1.0", id: Int64ID(1), result: []byte(`{"foo":"bar"}`)},
 			runtimeErr: true,
 			errMessage: "invalid jsonrpc version",
 		},
@@ -758,7 +803,7 @@ func TestResponse_Validate(t *testing.T) {
 			name: "Invalid ID type",
 			resp: &Response{
 				jsonrpc: "2.0",
-				id:      []int{1, 2, 3},
+				id:      toID([]int{1, 2, 3}),
 				result:  []byte(`{"foo":"bar"}`),
 			},
 			runtimeErr: true,
@@ -768,7 +813,7 @@ func TestResponse_Validate(t *testing.T) {
 			name: "Both result and error",
 			resp: &Response{
 				jsonrpc: "2.0",
-				id:      "first",
+				id:      StringID("first"),
 				result:  []byte(`{"foo":"bar"}`),
 				err:     &Error{Code: 123, Message: "test msg"},
 			},
@@ -777,7 +822,7 @@ func TestResponse_Validate(t *testing.T) {
 		},
 		{
 			name:       "Neither result nor error",
-			resp:       &Response{jsonrpc: "2.0", id: "first"},
+			resp:       &Response{jsonrpc: "2.0", id: StringID("first")},
 			runtimeErr: true,
 			errMessage: "response must contain either result or error",
 		},
@@ -887,7 +932,7 @@ func TestDecodeResponseFromReader(t *testing.T) {
 // Responses are immutable after decode, so concurrent access should never race.
 func TestResponse_Concurrency(t *testing.T) {
 	t.Run("Concurrent IDString", func(t *testing.T) {
-		resp := &Response{id: int64(12345)}
+		resp := &Response{id: Int64ID(12345)}
 
 		var wg sync.WaitGroup
 		for range 200 {
@@ -913,8 +958,8 @@ func TestResponse_Concurrency(t *testing.T) {
 	})
 
 	t.Run("Concurrent Equals", func(t *testing.T) {
-		resp1 := &Response{jsonrpc: "2.0", id: int64(1), result: []byte(`{"foo":"bar"}`)}
-		resp2 := &Response{jsonrpc: "2.0", id: int64(1), result: []byte(`{"foo":"bar"}`)}
+		resp1 := &Response{jsonrpc: "2.0", id: Int64ID(1), result: []byte(`{"foo":"bar"}`)}
+		resp2 := &Response{jsonrpc: "2.0", id: Int64ID(1), result: []byte(`{"foo":"bar"}`)}
 
 		var wg sync.WaitGroup
 		for range 200 {
@@ -1194,7 +1239,7 @@ func TestResponse_WriteTo(t *testing.T) {
 	t.Run("Response with rawError", func(t *testing.T) {
 		resp := &Response{
 			jsonrpc:  "2.0",
-			id:       int64(1),
+			id:       Int64ID(1),
 			rawError: []byte(`{"code":-32601,"message":"Method not found"}`),
 		}
 
@@ -1216,10 +1261,10 @@ func TestResponse_WriteTo(t *testing.T) {
 		assert.Equal(t, "Method not found", errMap["message"])
 	})
 
-	t.Run("Response with rawID", func(t *testing.T) {
+	t.Run("Response with string ID", func(t *testing.T) {
 		resp := &Response{
 			jsonrpc: "2.0",
-			rawID:   json.RawMessage(`"raw-id"`),
+			id:      StringID("raw-id"),
 			result:  json.RawMessage(`"result"`),
 		}
 
@@ -1309,7 +1354,7 @@ func TestResponse_WriteTo(t *testing.T) {
 	t.Run("Invalid response validation fails", func(t *testing.T) {
 		resp := &Response{
 			jsonrpc: "1.0",
-			id:      int64(1),
+			id:      Int64ID(1),
 			result:  json.RawMessage(`"test"`),
 		}
 
@@ -1331,6 +1376,48 @@ func TestResponse_WriteTo(t *testing.T) {
 	})
 }
 
+func TestResponse_AppendJSON(t *testing.T) {
+	t.Run("Matches WriteTo output", func(t *testing.T) {
+		resultResp, err := NewResponse("abc", map[string]any{"foo": "bar"})
+		require.NoError(t, err)
+		decoded, err := DecodeResponse(
+			[]byte(`{"jsonrpc":"2.0","id":5,"error":{"code":-32000,"message":"boom"}}`))
+		require.NoError(t, err)
+
+		cases := []*Response{
+			resultResp,
+			NewErrorResponse(int64(1), &Error{Code: -32601, Message: "Method not found"}),
+			decoded,
+		}
+
+		for _, resp := range cases {
+			var buf bytes.Buffer
+			_, err := resp.WriteTo(&buf)
+			require.NoError(t, err)
+
+			out, err := resp.AppendJSON(nil)
+			require.NoError(t, err)
+			assert.Equal(t, buf.String(), string(out))
+		}
+	})
+
+	t.Run("Appends to existing buffer", func(t *testing.T) {
+		resp, err := NewResponseFromRaw(int64(1), json.RawMessage(`"0x1"`))
+		require.NoError(t, err)
+
+		out, err := resp.AppendJSON([]byte("["))
+		require.NoError(t, err)
+		assert.Equal(t, `[{"jsonrpc":"2.0","id":1,"result":"0x1"}`, string(out))
+	})
+
+	t.Run("Invalid response leaves dst unchanged", func(t *testing.T) {
+		resp := &Response{jsonrpc: "1.0", result: []byte(`1`)}
+		out, err := resp.AppendJSON([]byte("prefix"))
+		require.Error(t, err)
+		assert.Equal(t, "prefix", string(out))
+	})
+}
+
 func TestResponse_PeekStringByPath(t *testing.T) {
 	t.Run("Extract top-level string field", func(t *testing.T) {
 		resp, err := NewResponse(1, map[string]any{
@@ -1629,6 +1716,444 @@ func TestResponse_PeekBytesByPath(t *testing.T) {
 	})
 }
 
+func TestResponse_PeekTypedByPath(t *testing.T) {
+	resp := mustDecodeResponse(t, `{"jsonrpc":"2.0","id":1,"result":{
+		"number":18446744073709551615,
+		"gas":21000,
+		"negative":-5,
+		"ratio":0.25,
+		"hex":"0x10",
+		"ok":true,
+		"failed":false,
+		"empty":null,
+		"txs":[{"h":"a"},{"h":"b"},{"h":"c"}],
+		"balances":{"x":1,"y":2}
+	}}`)
+
+	t.Run("Int64", func(t *testing.T) {
+		gas, err := resp.PeekInt64ByPath("gas")
+		require.NoError(t, err)
+		assert.Equal(t, int64(21000), gas)
+
+		neg, err := resp.PeekInt64ByPath("negative")
+		require.NoError(t, err)
+		assert.Equal(t, int64(-5), neg)
+
+		_, err = resp.PeekInt64ByPath("number")
+		assert.ErrorContains(t, err, "not an int64")
+		_, err = resp.PeekInt64ByPath("ratio")
+		assert.ErrorContains(t, err, "not an int64")
+		_, err = resp.PeekInt64ByPath("hex")
+		assert.ErrorContains(t, err, "not a number")
+	})
+
+	t.Run("Uint64", func(t *testing.T) {
+		n, err := resp.PeekUint64ByPath("number")
+		require.NoError(t, err)
+		assert.Equal(t, uint64(18446744073709551615), n)
+
+		_, err = resp.PeekUint64ByPath("negative")
+		assert.ErrorContains(t, err, "not a uint64")
+	})
+
+	t.Run("Float64", func(t *testing.T) {
+		f, err := resp.PeekFloat64ByPath("ratio")
+		require.NoError(t, err)
+		assert.InDelta(t, 0.25, f, 0)
+
+		f, err = resp.PeekFloat64ByPath("gas")
+		require.NoError(t, err)
+		assert.InDelta(t, 21000.0, f, 0)
+
+		_, err = resp.PeekFloat64ByPath("ok")
+		assert.Error(t, err)
+	})
+
+	t.Run("Bool", func(t *testing.T) {
+		ok, err := resp.PeekBoolByPath("ok")
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		failed, err := resp.PeekBoolByPath("failed")
+		require.NoError(t, err)
+		assert.False(t, failed)
+
+		_, err = resp.PeekBoolByPath("gas")
+		assert.ErrorContains(t, err, "not a boolean")
+	})
+
+	t.Run("Len", func(t *testing.T) {
+		n, err := resp.PeekLenByPath("txs")
+		require.NoError(t, err)
+		assert.Equal(t, 3, n)
+
+		n, err = resp.PeekLenByPath("balances")
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+
+		n, err = resp.PeekLenByPath()
+		require.NoError(t, err)
+		assert.Equal(t, 10, n)
+
+		_, err = resp.PeekLenByPath("hex")
+		assert.Error(t, err)
+	})
+
+	t.Run("Exists", func(t *testing.T) {
+		assert.True(t, resp.PeekExists("gas"))
+		assert.True(t, resp.PeekExists("empty"))
+		assert.True(t, resp.PeekExists("txs", 1, "h"))
+		assert.False(t, resp.PeekExists("missing"))
+		assert.False(t, resp.PeekExists("txs", 5))
+
+		errResp := NewErrorResponse(1, &Error{Code: -32000, Message: "error"})
+		assert.False(t, errResp.PeekExists())
+	})
+
+	t.Run("Path not found", func(t *testing.T) {
+		_, err := resp.PeekInt64ByPath("missing")
+		assert.ErrorContains(t, err, "path not found")
+	})
+}
+
+func TestResponse_PeekIterators(t *testing.T) {
+	resp := mustDecodeResponse(t, `{"jsonrpc":"2.0","id":1,"result":{
+		"txs":[{"h":"a"},"b",3],
+		"balances":{"x":1,"y":{"z":2}}
+	}}`)
+
+	t.Run("Elements of an array", func(t *testing.T) {
+		seq, err := resp.PeekElementsByPath("txs")
+		require.NoError(t, err)
+
+		var indices []int
+		var values []string
+		for i, raw := range seq {
+			indices = append(indices, i)
+			values = append(values, string(raw))
+		}
+		assert.Equal(t, []int{0, 1, 2}, indices)
+		assert.Equal(t, []string{`{"h":"a"}`, `"b"`, `3`}, values)
+	})
+
+	t.Run("Members of an object", func(t *testing.T) {
+		seq, err := resp.PeekMembersByPath("balances")
+		require.NoError(t, err)
+
+		var keys, values []string
+		for key, raw := range seq {
+			keys = append(keys, key)
+			values = append(values, string(raw))
+		}
+		assert.Equal(t, []string{"x", "y"}, keys)
+		assert.Equal(t, []string{`1`, `{"z":2}`}, values)
+	})
+
+	t.Run("Early stop", func(t *testing.T) {
+		seq, err := resp.PeekElementsByPath("txs")
+		require.NoError(t, err)
+
+		count := 0
+		for range seq {
+			count++
+			break
+		}
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("Invalid targets", func(t *testing.T) {
+		_, err := resp.PeekElementsByPath("txs", 1)
+		assert.ErrorContains(t, err, "not an array or object")
+
+		_, err = resp.PeekMembersByPath("txs")
+		assert.ErrorContains(t, err, "not an object")
+
+		_, err = resp.PeekElementsByPath("missing")
+		assert.ErrorContains(t, err, "path not found")
+	})
+}
+
+// lazyArrayResponse has arrays that are only parsed lazily when a response is decoded.
+const lazyArrayResponse = `{"jsonrpc":"2.0","id":1,"result":{"a":[1,2,3,4,5],"b":{"c":[6,7]}}}`
+
+func TestResponse_LazyArrays(t *testing.T) {
+	t.Run("PeekLenByPath", func(t *testing.T) {
+		resp := mustDecodeResponse(t, lazyArrayResponse)
+
+		n, err := resp.PeekLenByPath("a")
+		require.NoError(t, err)
+		assert.Equal(t, 5, n)
+		n, err = resp.PeekLenByPath("b", "c")
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+	})
+
+	t.Run("Query with negative indices", func(t *testing.T) {
+		resp := mustDecodeResponse(t, lazyArrayResponse)
+
+		values, err := resp.Query(MustCompilePath("a[-1]"))
+		require.NoError(t, err)
+		require.Len(t, values, 1)
+		assert.Equal(t, "5", string(values[0]))
+
+		value, err := resp.QueryInt64(MustCompilePath("b.c[-2]"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(6), value)
+	})
+
+	t.Run("SetByPath with negative indices", func(t *testing.T) {
+		resp := mustDecodeResponse(t, lazyArrayResponse)
+
+		out, err := resp.SetByPath("last", "a", -1)
+		require.NoError(t, err)
+		out, err = out.SetByPath(0, "b", "c", -2)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"a":[1,2,3,4,"last"],"b":{"c":[0,7]}}`, string(out.RawResult()))
+	})
+}
+
+func TestResponse_Query(t *testing.T) {
+	resp := mustDecodeResponse(t, `{"jsonrpc":"2.0","id":1,"result":{
+		"number":"0x10",
+		"gasUsed":21000,
+		"transactions":[
+			{"hash":"0xa","value":1,"meta":{"ok":true}},
+			{"hash":"0xb","value":5},
+			{"hash":"0xc","value":10,"meta":{"ok":false}}
+		],
+		"logs":[
+			{"removed":false,"data":"d1"},
+			{"removed":true,"data":"d2"},
+			{"removed":false,"data":"d3"}
+		],
+		"balances":{"x":1,"y":2},
+		"odd.key":"dotted"
+	}}`)
+
+	query := func(t *testing.T, expr string) []string {
+		t.Helper()
+		values, err := resp.Query(MustCompilePath(expr))
+		require.NoError(t, err)
+		out := make([]string, len(values))
+		for i, v := range values {
+			out[i] = string(v)
+		}
+		return out
+	}
+
+	t.Run("Keys and indices", func(t *testing.T) {
+		assert.Equal(t, []string{`"0x10"`}, query(t, "number"))
+		assert.Equal(t, []string{`"0xb"`}, query(t, "$.transactions[1].hash"))
+		assert.Equal(t, []string{`"0xc"`}, query(t, "transactions[-1].hash"))
+		assert.Equal(t, []string{`"dotted"`}, query(t, "$['odd.key']"))
+	})
+
+	t.Run("Wildcards", func(t *testing.T) {
+		assert.Equal(t, []string{`"0xa"`, `"0xb"`, `"0xc"`}, query(t, "transactions[*].hash"))
+		assert.Equal(t, []string{`1`, `2`}, query(t, "balances.*"))
+		assert.Equal(t, []string{`true`, `false`}, query(t, "transactions[*].meta.ok"))
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		assert.Equal(t, []string{`"d1"`, `"d3"`}, query(t, "logs[?(@.removed==false)].data"))
+		assert.Equal(t, []string{`"0xb"`, `"0xc"`}, query(t, "transactions[?(@.value > 1)].hash"))
+		assert.Equal(t, []string{`"0xa"`, `"0xc"`}, query(t, "transactions[?(@.meta)].hash"))
+		assert.Equal(t, []string{`"0xb"`}, query(t, `transactions[?(@.hash == "0xb")].hash`))
+		assert.Equal(t, []string{`1`}, query(t, "balances[?(@ < 2)]"))
+
+		// Values of different types only satisfy !=
+		assert.Empty(t, query(t, "transactions[?(@.value == '5')]"))
+		assert.Len(t, query(t, "transactions[?(@.value != '5')]"), 3)
+	})
+
+	t.Run("No match", func(t *testing.T) {
+		assert.Empty(t, query(t, "missing"))
+		assert.Empty(t, query(t, "transactions[7]"))
+		assert.Empty(t, query(t, "transactions[-4]"))
+		assert.Empty(t, query(t, "number[0]"))
+		assert.Empty(t, query(t, "number.*"))
+
+		_, err := resp.QueryFirst(MustCompilePath("missing"))
+		assert.ErrorContains(t, err, "path not found")
+	})
+
+	t.Run("Typed results", func(t *testing.T) {
+		s, err := resp.QueryString(MustCompilePath("transactions[0].hash"))
+		require.NoError(t, err)
+		assert.Equal(t, "0xa", s)
+
+		n, err := resp.QueryInt64(MustCompilePath("gasUsed"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(21000), n)
+
+		u, err := resp.QueryUint64(MustCompilePath("transactions[?(@.value>=10)].value"))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(10), u)
+
+		f, err := resp.QueryFloat64(MustCompilePath("balances.y"))
+		require.NoError(t, err)
+		assert.InDelta(t, 2.0, f, 0)
+
+		b, err := resp.QueryBool(MustCompilePath("logs[1].removed"))
+		require.NoError(t, err)
+		assert.True(t, b)
+
+		raw, err := resp.QueryFirst(MustCompilePath("transactions[*].value"))
+		require.NoError(t, err)
+		assert.Equal(t, "1", string(raw))
+
+		_, err = resp.QueryInt64(MustCompilePath("number"))
+		assert.Error(t, err)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := resp.Query(nil)
+		assert.Error(t, err)
+
+		errResp := NewErrorResponse(1, &Error{Code: -32000, Message: "error"})
+		_, err = errResp.Query(MustCompilePath("a"))
+		assert.ErrorContains(t, err, "no result field")
+	})
+}
+
+func TestResponse_SetByPath(t *testing.T) {
+	const data = `{"jsonrpc":"2.0","id":"req-1","result":{
+		"number":"0xABC",
+		"txs":[{"hash":"0xA"},{"hash":"0xB"}],
+		"extra":{"vendor":true}
+	}}`
+
+	t.Run("Replace existing values", func(t *testing.T) {
+		resp := mustDecodeResponse(t, data)
+
+		out, err := resp.SetByPath("0xabc", "number")
+		require.NoError(t, err)
+		out, err = out.SetByPath("0xb", "txs", -1, "hash")
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{
+			"number":"0xabc",
+			"txs":[{"hash":"0xA"},{"hash":"0xb"}],
+			"extra":{"vendor":true}
+		}`, string(out.RawResult()))
+		assert.Equal(t, StringID("req-1"), out.ID())
+
+		// The original response is untouched
+		number, err := resp.PeekStringByPath("number")
+		require.NoError(t, err)
+		assert.Equal(t, "0xABC", number)
+	})
+
+	t.Run("Inject new members", func(t *testing.T) {
+		resp := mustDecodeResponse(t, data)
+
+		out, err := resp.SetByPath(map[string]any{"n": 1}, "computed", "stats")
+		require.NoError(t, err)
+		out, err = out.SetByPath(json.RawMessage(`[1, 2]`), "txs", 0, "refs")
+		require.NoError(t, err)
+
+		stats, err := out.PeekInt64ByPath("computed", "stats", "n")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), stats)
+		refs, err := out.PeekLenByPath("txs", 0, "refs")
+		require.NoError(t, err)
+		assert.Equal(t, 2, refs)
+	})
+
+	t.Run("Replace whole result", func(t *testing.T) {
+		resp := mustDecodeResponse(t, data)
+
+		out, err := resp.SetByPath([]int{1, 2})
+		require.NoError(t, err)
+		assert.JSONEq(t, `[1,2]`, string(out.RawResult()))
+	})
+
+	t.Run("Marshal round trip", func(t *testing.T) {
+		resp := mustDecodeResponse(t, data)
+
+		out, err := resp.SetByPath(nil, "extra")
+		require.NoError(t, err)
+
+		encoded, err := out.MarshalJSON()
+		require.NoError(t, err)
+		decoded, err := DecodeResponse(encoded)
+		require.NoError(t, err)
+		assert.True(t, out.Equals(decoded))
+		assert.True(t, decoded.PeekExists("extra"))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		resp := mustDecodeResponse(t, data)
+
+		_, err := resp.SetByPath(1, "txs", 5)
+		assert.ErrorContains(t, err, "path not found")
+		_, err = resp.SetByPath(1, "number", "x")
+		assert.ErrorContains(t, err, "non-object")
+		_, err = resp.SetByPath(1, "extra", 0)
+		assert.ErrorContains(t, err, "non-array")
+		_, err = resp.SetByPath(1, 1.5)
+		assert.ErrorContains(t, err, "string or int")
+		_, err = resp.SetByPath(json.RawMessage(`{bad`), "x")
+		assert.ErrorContains(t, err, "invalid value")
+		_, err = resp.SetByPath(make(chan int), "x")
+		assert.Error(t, err)
+
+		errResp := NewErrorResponse(1, &Error{Code: -32000, Message: "error"})
+		_, err = errResp.SetByPath(1, "x")
+		assert.ErrorContains(t, err, "no result field")
+	})
+}
+
+func TestResponse_DeleteByPath(t *testing.T) {
+	resp := mustDecodeResponse(t, `{"jsonrpc":"2.0","id":1,"result":{
+		"a":1,
+		"list":[1,2,3],
+		"nested":{"x":{"y":1,"z":2}}
+	}}`)
+
+	t.Run("Delete members and elements", func(t *testing.T) {
+		out, err := resp.DeleteByPath("a")
+		require.NoError(t, err)
+		out, err = out.DeleteByPath("list", 0)
+		require.NoError(t, err)
+		out, err = out.DeleteByPath("nested", "x", "z")
+		require.NoError(t, err)
+
+		assert.JSONEq(t, `{"list":[2,3],"nested":{"x":{"y":1}}}`, string(out.RawResult()))
+		assert.True(t, resp.PeekExists("a"), "original is untouched")
+	})
+
+	t.Run("Negative index", func(t *testing.T) {
+		out, err := resp.DeleteByPath("list", -1)
+		require.NoError(t, err)
+		assert.JSONEq(t, `[1,2]`, mustPeekBytes(t, out, "list"))
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		_, err := resp.DeleteByPath("missing")
+		assert.ErrorContains(t, err, "path not found")
+		_, err = resp.DeleteByPath("list", 3)
+		assert.ErrorContains(t, err, "path not found")
+		_, err = resp.DeleteByPath("nested", "missing", "x")
+		assert.ErrorContains(t, err, "path not found")
+		_, err = resp.DeleteByPath()
+		assert.Error(t, err)
+	})
+}
+
+func mustPeekBytes(t *testing.T, resp *Response, path ...any) string {
+	t.Helper()
+	raw, err := resp.PeekBytesByPath(path...)
+	require.NoError(t, err)
+	return string(raw)
+}
+func mustDecodeResponse(t *testing.T, data string) *Response {
+	t.Helper()
+	resp, err := DecodeResponse([]byte(data))
+	require.NoError(t, err)
+	return resp
+}
+
 func TestResponse_Clone(t *testing.T) {
 	t.Run("Clone response with result", func(t *testing.T) {
 		original, err := NewResponse("test-id", map[string]string{"key": "value"})
@@ -1711,8 +2236,8 @@ func TestResponse_Clone(t *testing.T) {
 		}
 	})
 
-	t.Run("Deep copy with rawID", func(t *testing.T) {
-		// Create response via decoding to ensure rawID is set
+	t.Run("Deep copy with decoded ID", func(t *testing.T) {
+		// Create response via decoding to ensure the raw ID is kept
 		data := []byte(`{"jsonrpc":"2.0","id":"test-id","result":"data"}`)
 		original, err := DecodeResponse(data)
 		require.NoError(t, err)
@@ -1720,7 +2245,7 @@ func TestResponse_Clone(t *testing.T) {
 		clone, err := original.Clone()
 		require.NoError(t, err)
 
-		// Verify rawID is copied
+		// Verify the ID is copied
 		assert.Equal(t, original.IDOrNil(), clone.IDOrNil())
 
 		// Modify clone and verify original is unaffected
@@ -1906,7 +2431,7 @@ func TestResponse_WithID(t *testing.T) {
 		updated, err := resp.WithID(struct{}{})
 		require.Error(t, err)
 		assert.Nil(t, updated)
-		assert.Contains(t, err.Error(), "invalid response after id update")
+		assert.Contains(t, err.Error(), "invalid id")
 	})
 
 	t.Run("nil receiver returns error", func(t *testing.T) {
@@ -2092,7 +2617,7 @@ func TestResponse_Size(t *testing.T) {
 		}
 	})
 
-	t.Run("Size with rawID from decoding", func(t *testing.T) {
+	t.Run("Size with raw ID from decoding", func(t *testing.T) {
 		data := []byte(`{"jsonrpc":"2.0","id":"decoded-id","result":"data"}`)
 		resp, err := DecodeResponse(data)
 		require.NoError(t, err)
@@ -2129,6 +2654,31 @@ func TestResponse_Size(t *testing.T) {
 	})
 }
 
+func TestResponse_Reset(t *testing.T) {
+	t.Run("Clears all fields", func(t *testing.T) {
+		resp, err := DecodeResponse([]byte(`{"jsonrpc":"2.0","id":1,"result":{"a":"b"}}`))
+		require.NoError(t, err)
+		_, err = resp.PeekStringByPath("a")
+		require.NoError(t, err)
+
+		resp.Reset()
+		assert.Empty(t, resp.jsonrpc)
+		assert.True(t, resp.id.IsAbsent())
+		assert.Nil(t, resp.err)
+		assert.Nil(t, resp.result)
+		assert.True(t, resp.id.IsAbsent())
+		assert.Nil(t, resp.rawError)
+
+		_, err = resp.PeekStringByPath("a")
+		assert.Error(t, err, "AST cache should be cleared")
+	})
+
+	t.Run("Nil receiver", func(_ *testing.T) {
+		var resp *Response
+		resp.Reset()
+	})
+}
+
 func TestResponse_Free(t *testing.T) {
 	t.Run("Free releases byte slices", func(t *testing.T) {
 		resp, err := NewResponse(1, map[string]string{"key": "value"})
@@ -2212,18 +2762,18 @@ func TestResponse_Free(t *testing.T) {
 		})
 	})
 
-	t.Run("Free with rawID and rawError fields", func(t *testing.T) {
-		// Create a response by unmarshaling (which populates rawID, rawError)
+	t.Run("Free with raw ID and rawError fields", func(t *testing.T) {
+		// Create a response by unmarshaling (which populates the ID and rawError)
 		data := []byte(`{"jsonrpc":"2.0","id":"test-id","error":{"code":-32000,"message":"error"}}`)
 		resp, err := DecodeResponse(data)
 		require.NoError(t, err)
 
-		// IDRaw() triggers unmarshal, which populates r.id from r.rawID
+		// IDOrNil() converts the parsed ID to a plain Go value
 		// So IDRaw() returns the parsed ID, not the raw bytes
 		idBefore := resp.IDOrNil()
 		assert.Equal(t, "test-id", idBefore)
 
-		// Call Free - releases rawID but keeps r.id
+		// Call Free - releases payload bytes but keeps r.id
 		resp.Free()
 
 		// IDOrNil still returns the parsed value (kept for logging)
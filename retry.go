@@ -0,0 +1,253 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"path"
+	"slices"
+	"time"
+)
+
+const (
+	// DefaultRetryAttempts is the default total number of attempts of a RetryPolicy.
+	DefaultRetryAttempts = 3
+
+	// DefaultInitialBackoff is the default delay before the first retry.
+	DefaultInitialBackoff = 100 * time.Millisecond
+
+	// DefaultMaxBackoff is the default upper bound of the delay between attempts.
+	DefaultMaxBackoff = 5 * time.Second
+
+	// defaultBackoffMultiplier is the default growth factor of the delay between attempts.
+	defaultBackoffMultiplier = 2
+)
+
+// RetryPolicy retries requests whose failure is known to be transient, but only for methods
+// declared idempotent, since a failed attempt may still have been executed upstream. An attempt
+// is retried if it fails with:
+//   - a transport error, i.e. a net.Error or an unexpected EOF, or the expiry of AttemptTimeout
+//   - an *HTTPStatusError with a 5xx or 429 status
+//   - an error response with one of the RetryCodes
+//
+// The delay before each retry grows exponentially from InitialBackoff by Multiplier up to
+// MaxBackoff, and is randomized between half and all of its value to spread out retries of
+// concurrent callers. A longer delay requested by a Retry-After header is respected, unless it
+// exceeds MaxBackoff or the deadline of the context, in which case the failure is returned
+// without retrying.
+//
+// The zero value retries nothing, as no method is declared idempotent. A RetryPolicy must not be
+// modified while in use.
+//
+// Example usage:
+//
+//	policy := &jsonrpc.RetryPolicy{
+//		IdempotentMethods: []string{"eth_get*", "eth_call", "eth_blockNumber"},
+//		RetryCodes:        []int{-32005},
+//		AttemptTimeout:    2 * time.Second,
+//	}
+//	client = policy.Wrap(client)
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one. Defaults to
+	// DefaultRetryAttempts.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry. Defaults to DefaultInitialBackoff.
+	InitialBackoff time.Duration
+
+	// MaxBackoff bounds the delay between attempts, including delays requested by Retry-After
+	// headers. Defaults to DefaultMaxBackoff.
+	MaxBackoff time.Duration
+
+	// Multiplier is the growth factor of the delay between attempts. Defaults to 2.
+	Multiplier float64
+
+	// AttemptTimeout, if set, bounds the duration of each attempt.
+	AttemptTimeout time.Duration
+
+	// IdempotentMethods are the methods that may be retried, as exact names or patterns in the
+	// syntax of path.Match, e.g. "eth_get*".
+	IdempotentMethods []string
+
+	// RetryCodes are the JSON-RPC error codes that are retried, e.g. -32005 for limit exceeded.
+	RetryCodes []int
+
+	// RegenerateIDs makes each retry use a new ID from IDGenerator instead of the ID of the
+	// request. Responses are returned with the ID of the request either way.
+	RegenerateIDs bool
+
+	// IDGenerator generates the IDs of retries if RegenerateIDs is set. Defaults to the
+	// package-wide IDGenerator.
+	IDGenerator IDGenerator
+}
+
+// Wrap returns a HandlerFunc that calls next and retries failed attempts according to the
+// policy. If all attempts fail, the outcome of the last attempt is returned. Notifications are
+// never retried.
+func (p *RetryPolicy) Wrap(next HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, req *Request) (*Response, error) {
+		if req == nil || req.IsNotification() || !p.IsIdempotent(req.Method) {
+			return next(ctx, req)
+		}
+
+		attempts := positiveOr(p.MaxAttempts, DefaultRetryAttempts)
+		attemptReq := req
+		var resp *Response
+		var err error
+		for attempt := 0; ; attempt++ {
+			resp, err = p.attempt(ctx, next, attemptReq)
+			if attempt+1 >= attempts || !p.retryable(ctx, resp, err) {
+				break
+			}
+			delay, ok := p.delay(attempt, err)
+			if !ok || !waitRetry(ctx, delay) {
+				break
+			}
+			if p.RegenerateIDs {
+				if attemptReq, err = req.WithID(p.nextID()); err != nil {
+					return nil, fmt.Errorf("failed to regenerate request id: %w", err)
+				}
+			}
+		}
+
+		if resp != nil && attemptReq != req {
+			return resp.WithID(req.ID)
+		}
+		return resp, err
+	}
+}
+
+// IsIdempotent returns true if method matches one of the IdempotentMethods.
+func (p *RetryPolicy) IsIdempotent(method string) bool {
+	return matchMethod(p.IdempotentMethods, method)
+}
+
+// attempt calls next once, bounded by the attempt timeout.
+func (p *RetryPolicy) attempt(
+	ctx context.Context,
+	next HandlerFunc,
+	req *Request,
+) (*Response, error) {
+	if p.AttemptTimeout <= 0 {
+		return next(ctx, req)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, p.AttemptTimeout)
+	defer cancel()
+	return next(attemptCtx, req)
+}
+
+// retryable classifies the outcome of an attempt.
+func (p *RetryPolicy) retryable(ctx context.Context, resp *Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return isTransientError(err)
+	}
+
+	if resp == nil {
+		return false
+	}
+	rpcErr := resp.Err()
+	return rpcErr != nil && slices.Contains(p.RetryCodes, rpcErr.Code)
+}
+
+// isTransientError returns true if err is a failure that may not recur on another attempt. Other
+// errors, e.g. failures to encode a request or decode a response, are returned right away. As the
+// parent context is known to be alive, a deadline error can only stem from the attempt timeout.
+func isTransientError(err error) bool {
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError ||
+			statusErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// delay returns the delay before the retry following the given attempt, counted from zero, and
+// false if the upstream requested a delay longer than MaxBackoff.
+func (p *RetryPolicy) delay(attempt int, err error) (time.Duration, bool) {
+	backoff := p.backoff(attempt, rand.Float64())
+
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > backoff {
+		if statusErr.RetryAfter > positiveOr(p.MaxBackoff, DefaultMaxBackoff) {
+			return 0, false
+		}
+		return statusErr.RetryAfter, true
+	}
+	return backoff, true
+}
+
+// backoff returns the jittered exponential backoff for the given attempt, where jitter is a value
+// from 0 to 1 selecting a delay between half and all of the backoff.
+func (p *RetryPolicy) backoff(attempt int, jitter float64) time.Duration {
+	initial := positiveOr(p.InitialBackoff, DefaultInitialBackoff)
+	maxBackoff := positiveOr(p.MaxBackoff, DefaultMaxBackoff)
+	multiplier := p.Multiplier
+	if multiplier <= 0 {
+		multiplier = defaultBackoffMultiplier
+	}
+
+	backoff := float64(initial)
+	for range attempt {
+		backoff *= multiplier
+		if backoff >= float64(maxBackoff) {
+			break
+		}
+	}
+	backoff = min(backoff, float64(maxBackoff))
+
+	return time.Duration(backoff/2 + backoff/2*jitter)
+}
+
+// waitRetry sleeps for delay, and returns false if ctx is done first, or without sleeping if its
+// deadline is too close to allow another attempt after the delay.
+func waitRetry(ctx context.Context, delay time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= delay {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// nextID returns the ID of a retry.
+func (p *RetryPolicy) nextID() ID {
+	if p.IDGenerator != nil {
+		return p.IDGenerator.NextID()
+	}
+	return nextID()
+}
+
+// matchMethod returns true if method matches one of the patterns, which are exact names or
+// patterns in the syntax of path.Match.
+func matchMethod(patterns []string, method string) bool {
+	for _, pattern := range patterns {
+		if pattern == method {
+			return true
+		}
+		if ok, _ := path.Match(pattern, method); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,257 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedHandler returns a HandlerFunc that plays back the outcomes in order, repeating the last
+// one, and records the requests it receives.
+func scriptedHandler(outcomes ...func(req *Request) (*Response, error)) (HandlerFunc, *[]*Request) {
+	var received []*Request
+	return func(_ context.Context, req *Request) (*Response, error) {
+		received = append(received, req)
+		outcome := outcomes[min(len(received), len(outcomes))-1]
+		return outcome(req)
+	}, &received
+}
+
+func succeed(req *Request) (*Response, error) {
+	return NewResponse(req.ID, "ok")
+}
+
+func failWith(err error) func(*Request) (*Response, error) {
+	return func(*Request) (*Response, error) { return nil, err }
+}
+
+func respondCode(code int) func(*Request) (*Response, error) {
+	return func(req *Request) (*Response, error) {
+		return NewErrorResponse(req.ID, &Error{Code: code, Message: "error"}), nil
+	}
+}
+
+// errReset is a transport error.
+var errReset = &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+
+// errDecode is a failure to decode a response, which recurs on every attempt.
+var errDecode = &DecodeError{Code: ParseError, Err: errors.New("unexpected end of input")}
+
+// fastRetryPolicy returns a policy with negligible backoff, retrying the method "m".
+func fastRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		InitialBackoff:    time.Microsecond,
+		MaxBackoff:        time.Microsecond,
+		IdempotentMethods: []string{"m"},
+		RetryCodes:        []int{-32005},
+	}
+}
+
+func TestRetryPolicy_Classification(t *testing.T) {
+	cases := map[string]struct {
+		outcome func(*Request) (*Response, error)
+		retried bool
+	}{
+		"Transport error":  {failWith(errReset), true},
+		"Unexpected EOF":   {failWith(fmt.Errorf("read: %w", io.ErrUnexpectedEOF)), true},
+		"Attempt timeout":  {failWith(context.DeadlineExceeded), true},
+		"Encode error":     {failWith(errors.New("failed to encode request")), false},
+		"Decode error":     {failWith(fmt.Errorf("decode: %w", errDecode)), false},
+		"Status 503":       {failWith(wrapStatus(http.StatusServiceUnavailable)), true},
+		"Status 429":       {failWith(&HTTPStatusError{StatusCode: http.StatusTooManyRequests}), true},
+		"Status 400":       {failWith(&HTTPStatusError{StatusCode: http.StatusBadRequest}), false},
+		"Retry code":       {respondCode(-32005), true},
+		"Other code":       {respondCode(InvalidParams), false},
+		"Success":          {succeed, false},
+		"Missing response": {func(*Request) (*Response, error) { return nil, nil }, false},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			next, received := scriptedHandler(tc.outcome, succeed)
+			_, _ = fastRetryPolicy().Wrap(next)(context.Background(), NewRequest("m", nil))
+
+			if tc.retried {
+				assert.Len(t, *received, 2)
+			} else {
+				assert.Len(t, *received, 1)
+			}
+		})
+	}
+}
+
+// wrapStatus returns a wrapped *HTTPStatusError with the given status code.
+func wrapStatus(code int) error {
+	return fmt.Errorf("upstream: %w", &HTTPStatusError{StatusCode: code})
+}
+
+func TestRetryPolicy_Wrap(t *testing.T) {
+	t.Run("Succeeds after retries", func(t *testing.T) {
+		next, received := scriptedHandler(failWith(errReset), respondCode(-32005), succeed)
+		resp, err := fastRetryPolicy().Wrap(next)(context.Background(), NewRequestWithID("m", nil, 1))
+		require.NoError(t, err)
+		assert.Nil(t, resp.Err())
+		assert.Len(t, *received, 3)
+		for _, req := range *received {
+			assert.Equal(t, "1", req.IDString(), "ids should be preserved by default")
+		}
+	})
+
+	t.Run("Returns the last outcome", func(t *testing.T) {
+		next, received := scriptedHandler(respondCode(-32005))
+		policy := fastRetryPolicy()
+		policy.MaxAttempts = 4
+		resp, err := policy.Wrap(next)(context.Background(), NewRequest("m", nil))
+		require.NoError(t, err)
+		assert.Equal(t, -32005, resp.Err().Code)
+		assert.Len(t, *received, 4)
+
+		next, _ = scriptedHandler(failWith(errReset))
+		_, err = policy.Wrap(next)(context.Background(), NewRequest("m", nil))
+		assert.ErrorIs(t, err, errReset)
+	})
+
+	t.Run("Non-idempotent methods are not retried", func(t *testing.T) {
+		next, received := scriptedHandler(failWith(errReset), succeed)
+		_, err := fastRetryPolicy().Wrap(next)(context.Background(), NewRequest("send", nil))
+		assert.Error(t, err)
+		assert.Len(t, *received, 1)
+
+		next, received = scriptedHandler(failWith(errReset), succeed)
+		_, err = fastRetryPolicy().Wrap(next)(context.Background(), NewNotification("m", nil))
+		assert.Error(t, err)
+		assert.Len(t, *received, 1)
+	})
+
+	t.Run("Regenerates ids", func(t *testing.T) {
+		next, received := scriptedHandler(failWith(errReset), succeed)
+		policy := fastRetryPolicy()
+		policy.RegenerateIDs = true
+		policy.IDGenerator = NewPrefixedIDGenerator("retry-")
+
+		resp, err := policy.Wrap(next)(context.Background(), NewRequestWithID("m", nil, "orig"))
+		require.NoError(t, err)
+		assert.Equal(t, "orig", resp.IDString())
+		require.Len(t, *received, 2)
+		assert.Equal(t, "orig", (*received)[0].IDString())
+		assert.Equal(t, "retry-1", (*received)[1].IDString())
+	})
+
+	t.Run("Attempt timeout", func(t *testing.T) {
+		attempts := 0
+		next := func(ctx context.Context, req *Request) (*Response, error) {
+			attempts++
+			if attempts == 1 {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return succeed(req)
+		}
+		policy := fastRetryPolicy()
+		policy.AttemptTimeout = 10 * time.Millisecond
+
+		resp, err := policy.Wrap(next)(context.Background(), NewRequest("m", nil))
+		require.NoError(t, err)
+		assert.Nil(t, resp.Err())
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("Canceled context stops retries", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		next := func(context.Context, *Request) (*Response, error) {
+			cancel()
+			return nil, context.Canceled
+		}
+		calls := 0
+		counted := func(ctx context.Context, req *Request) (*Response, error) {
+			calls++
+			return next(ctx, req)
+		}
+		_, err := fastRetryPolicy().Wrap(counted)(ctx, NewRequest("m", nil))
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Retry-After beyond the deadline stops retries", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		next, received := scriptedHandler(failWith(&HTTPStatusError{
+			StatusCode: http.StatusTooManyRequests,
+			RetryAfter: time.Minute,
+		}))
+		policy := fastRetryPolicy()
+		policy.MaxBackoff = time.Hour
+
+		start := time.Now()
+		_, err := policy.Wrap(next)(ctx, NewRequest("m", nil))
+		assert.Error(t, err)
+		assert.Len(t, *received, 1)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+
+	t.Run("Retry-After beyond MaxBackoff stops retries", func(t *testing.T) {
+		next, received := scriptedHandler(failWith(&HTTPStatusError{
+			StatusCode: http.StatusTooManyRequests,
+			RetryAfter: time.Hour,
+		}))
+
+		start := time.Now()
+		_, err := fastRetryPolicy().Wrap(next)(context.Background(), NewRequest("m", nil))
+		var statusErr *HTTPStatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Len(t, *received, 1)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+}
+
+func TestRetryPolicy_HTTPUpstream(t *testing.T) {
+	server := newEchoServer(t)
+	upstream, err := NewHTTPUpstream(HTTPUpstreamConfig{URL: server.URL})
+	require.NoError(t, err)
+	server.Close()
+
+	_, err = upstream.Call(context.Background(), NewRequest("m", nil))
+	require.Error(t, err)
+	assert.True(t, isTransientError(err), "connection errors should be retried")
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	assert.Equal(t, 50*time.Millisecond, policy.backoff(0, 0))
+	assert.Equal(t, 100*time.Millisecond, policy.backoff(0, 1))
+	assert.Equal(t, 400*time.Millisecond, policy.backoff(2, 1))
+	assert.Equal(t, time.Second, policy.backoff(10, 1))
+	assert.Equal(t, 500*time.Millisecond, policy.backoff(100, 0))
+
+	retryAfter := &HTTPStatusError{StatusCode: http.StatusTooManyRequests, RetryAfter: time.Second}
+	delay, ok := policy.delay(0, retryAfter)
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, delay)
+	retryAfter.RetryAfter = 3 * time.Second
+	_, ok = policy.delay(0, retryAfter)
+	assert.False(t, ok, "Retry-After beyond MaxBackoff should not be waited for")
+	delay, ok = policy.delay(0, errReset)
+	assert.True(t, ok)
+	assert.LessOrEqual(t, delay, 100*time.Millisecond)
+
+	defaults := &RetryPolicy{}
+	assert.Equal(t, DefaultInitialBackoff, defaults.backoff(0, 1))
+	assert.Equal(t, DefaultMaxBackoff, defaults.backoff(20, 1))
+}
+
+func TestRetryPolicy_IsIdempotent(t *testing.T) {
+	policy := &RetryPolicy{IdempotentMethods: []string{"eth_get*", "eth_call"}}
+	assert.True(t, policy.IsIdempotent("eth_getBalance"))
+	assert.True(t, policy.IsIdempotent("eth_call"))
+	assert.False(t, policy.IsIdempotent("eth_sendRawTransaction"))
+	assert.False(t, (&RetryPolicy{}).IsIdempotent("eth_call"))
+}
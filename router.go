@@ -0,0 +1,241 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// RouterConfig configures a Router.
+type RouterConfig struct {
+	// Default receives the requests whose method matches no route. If nil, such requests are
+	// answered with a MethodNotFound error response.
+	Default Upstream
+
+	// ErrorHandler, if set, is called with the failures of sub-batches whose requests are
+	// answered with error responses because other sub-batches of the same batch succeeded.
+	ErrorHandler func(err error)
+}
+
+// Router is an Upstream that routes requests to upstreams by method. Routes are registered with
+// Handle using one of three kinds of patterns, which are matched in this order:
+//   - exact method names, e.g. "eth_blockNumber"
+//   - prefixes, written as a pattern ending in a single "*", e.g. "debug_*", where the longest
+//     matching prefix wins
+//   - glob patterns in the syntax of path.Match, e.g. "eth_get*By?ash", where the first
+//     registered matching pattern wins
+//
+// Batches are split into one sub-batch per upstream, which are sent concurrently, and the
+// responses are reassembled into a single batch in the order of the requests. A Router can be
+// used as the Upstream of a ReverseProxy.
+//
+// Requests are grouped into sub-batches by comparing upstreams with ==, so upstreams must be of
+// comparable types, e.g. pointers. Routes must be registered before the Router is used. Once
+// registered, a Router is safe for concurrent use.
+//
+// Example usage:
+//
+//	router := jsonrpc.NewRouter(jsonrpc.RouterConfig{Default: fullNode})
+//	err := router.Handle("debug_*", archiveNode)
+//	err = router.Handle("eth_blockNumber", lightNode)
+type Router struct {
+	exact    map[string]Upstream
+	prefixes []patternRoute // Sorted by descending prefix length
+	globs    []patternRoute // In order of registration
+
+	fallback     Upstream
+	errorHandler func(error)
+}
+
+// patternRoute is a prefix or glob route.
+type patternRoute struct {
+	pattern  string
+	upstream Upstream
+}
+
+// NewRouter creates a Router without routes.
+func NewRouter(config RouterConfig) *Router {
+	return &Router{
+		exact:        make(map[string]Upstream),
+		fallback:     config.Default,
+		errorHandler: config.ErrorHandler,
+	}
+}
+
+// Handle registers a route from pattern to upstream. See Router for the pattern syntax. Returns an
+// error if the pattern is malformed or already registered.
+func (r *Router) Handle(pattern string, upstream Upstream) error {
+	if pattern == "" {
+		return errors.New("pattern cannot be empty")
+	}
+	if upstream == nil {
+		return errors.New("upstream cannot be nil")
+	}
+
+	switch kind := strings.IndexAny(pattern, `*?[\`); {
+	case kind < 0:
+		if _, ok := r.exact[pattern]; ok {
+			return fmt.Errorf("pattern %q is already registered", pattern)
+		}
+		r.exact[pattern] = upstream
+	case kind == len(pattern)-1 && pattern[kind] == '*':
+		prefix := pattern[:kind]
+		if slices.ContainsFunc(r.prefixes, func(p patternRoute) bool { return p.pattern == prefix }) {
+			return fmt.Errorf("pattern %q is already registered", pattern)
+		}
+		r.prefixes = append(r.prefixes, patternRoute{pattern: prefix, upstream: upstream})
+		slices.SortStableFunc(r.prefixes, func(a, b patternRoute) int {
+			return len(b.pattern) - len(a.pattern)
+		})
+	default:
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		if slices.ContainsFunc(r.globs, func(p patternRoute) bool { return p.pattern == pattern }) {
+			return fmt.Errorf("pattern %q is already registered", pattern)
+		}
+		r.globs = append(r.globs, patternRoute{pattern: pattern, upstream: upstream})
+	}
+
+	return nil
+}
+
+// Match returns the upstream that requests for method are routed to, and false if there is none.
+func (r *Router) Match(method string) (Upstream, bool) {
+	if upstream, ok := r.exact[method]; ok {
+		return upstream, true
+	}
+	for _, route := range r.prefixes {
+		if strings.HasPrefix(method, route.pattern) {
+			return route.upstream, true
+		}
+	}
+	for _, route := range r.globs {
+		if ok, _ := path.Match(route.pattern, method); ok {
+			return route.upstream, true
+		}
+	}
+	if r.fallback != nil {
+		return r.fallback, true
+	}
+	return nil, false
+}
+
+// Call routes a single request to its upstream. Requests without a route are answered with a
+// MethodNotFound error response.
+func (r *Router) Call(ctx context.Context, req *Request) (*Response, error) {
+	if req == nil {
+		return nil, errors.New("request cannot be nil")
+	}
+
+	upstream, ok := r.Match(req.Method)
+	if !ok {
+		return methodNotFoundResponse(req), nil
+	}
+	return upstream.Call(ctx, req)
+}
+
+// CallBatch splits the requests into one sub-batch per upstream, sends the sub-batches
+// concurrently, and returns the responses in the order of the requests. The IDs of the requests
+// must be unique within the batch.
+//
+// Requests without a route are answered with a MethodNotFound error response, and requests
+// without a response from their upstream with an error response. If every sub-batch fails, the
+// joined errors are returned instead.
+func (r *Router) CallBatch(ctx context.Context, reqs []*Request) ([]*Response, error) {
+	groups, order := r.split(reqs)
+
+	results := make([][]*Response, len(groups))
+	errs := make([]error, len(groups))
+	if len(groups) == 1 {
+		results[0], errs[0] = groups[0].upstream.CallBatch(ctx, groups[0].reqs)
+	} else {
+		var wg sync.WaitGroup
+		for i, group := range groups {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i], errs[i] = group.upstream.CallBatch(ctx, group.reqs)
+			}()
+		}
+		wg.Wait()
+	}
+
+	failed := 0
+	byID := make(map[ID]*Response, len(reqs))
+	for i, err := range errs {
+		if err != nil {
+			failed++
+			continue
+		}
+		for _, resp := range results[i] {
+			byID[resp.ID()] = resp
+		}
+	}
+	if failed > 0 && failed == len(groups) {
+		return nil, errors.Join(errs...)
+	}
+	for _, err := range errs {
+		if err != nil && r.errorHandler != nil {
+			r.errorHandler(err)
+		}
+	}
+
+	out := make([]*Response, 0, len(reqs))
+	for i, req := range reqs {
+		switch {
+		case req.IsNotification():
+			continue
+		case order[i] < 0:
+			out = append(out, methodNotFoundResponse(req))
+		case byID[req.ID] != nil:
+			out = append(out, byID[req.ID])
+		default:
+			out = append(out, upstreamErrorResponse(req.ID))
+		}
+	}
+
+	return out, nil
+}
+
+// routeGroup is the sub-batch of requests routed to one upstream.
+type routeGroup struct {
+	upstream Upstream
+	reqs     []*Request
+}
+
+// split groups the requests by upstream, in order of first appearance, and returns for each
+// request the index of its group, or -1 if it has no route.
+func (r *Router) split(reqs []*Request) ([]routeGroup, []int) {
+	var groups []routeGroup
+	order := make([]int, len(reqs))
+	for i, req := range reqs {
+		upstream, ok := r.Match(req.Method)
+		if !ok {
+			order[i] = -1
+			continue
+		}
+
+		index := slices.IndexFunc(groups, func(g routeGroup) bool { return g.upstream == upstream })
+		if index < 0 {
+			index = len(groups)
+			groups = append(groups, routeGroup{upstream: upstream})
+		}
+		groups[index].reqs = append(groups[index].reqs, req)
+		order[i] = index
+	}
+	return groups, order
+}
+
+// methodNotFoundResponse returns the response to a request without a route, or nil for a
+// notification.
+func methodNotFoundResponse(req *Request) *Response {
+	if req.IsNotification() {
+		return nil
+	}
+	return NewErrorResponse(req.ID, &Error{Code: MethodNotFound, Message: msgMethodNotFound})
+}
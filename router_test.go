@@ -0,0 +1,191 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resultNames returns the string results of the responses, or the error messages for error
+// responses.
+func resultNames(t *testing.T, resps []*Response) []string {
+	t.Helper()
+
+	names := make([]string, 0, len(resps))
+	for _, resp := range resps {
+		if resp.Err() != nil {
+			names = append(names, resp.Err().Message)
+			continue
+		}
+		var name string
+		require.NoError(t, resp.UnmarshalResult(&name))
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestRouter_Match(t *testing.T) {
+	archive := &fakeUpstream{name: "archive"}
+	light := &fakeUpstream{name: "light"}
+	trace := &fakeUpstream{name: "trace"}
+	glob := &fakeUpstream{name: "glob"}
+
+	router := NewRouter(RouterConfig{})
+	require.NoError(t, router.Handle("debug_*", archive))
+	require.NoError(t, router.Handle("debug_trace*", trace))
+	require.NoError(t, router.Handle("eth_blockNumber", light))
+	require.NoError(t, router.Handle("eth_get*By?ash", glob))
+	require.NoError(t, router.Handle("eth_*ByHash", archive))
+
+	cases := map[string]Upstream{
+		"eth_blockNumber":           light,
+		"debug_getRawBlock":         archive,
+		"debug_traceTransaction":    trace,
+		"eth_getBlockByHash":        glob,
+		"eth_getTransactionByHash":  glob,
+		"eth_uncleCountByHash":      archive,
+		"eth_blockNumberAndMore":    nil,
+		"eth_getBlockByNumber":      nil,
+		"net_version":               nil,
+		"debug":                     nil,
+		"eth_getBlockByHash_suffix": nil,
+	}
+	for method, want := range cases {
+		got, ok := router.Match(method)
+		if want == nil {
+			assert.False(t, ok, method)
+			continue
+		}
+		assert.True(t, ok, method)
+		assert.Same(t, want, got, method)
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		fallback := &fakeUpstream{name: "fallback"}
+		router := NewRouter(RouterConfig{Default: fallback})
+		got, ok := router.Match("net_version")
+		assert.True(t, ok)
+		assert.Same(t, fallback, got)
+	})
+
+	t.Run("Invalid patterns", func(t *testing.T) {
+		assert.Error(t, router.Handle("", light))
+		assert.Error(t, router.Handle("m", nil))
+		assert.Error(t, router.Handle("eth_[", light))
+		assert.Error(t, router.Handle("eth_blockNumber", light))
+		assert.Error(t, router.Handle("debug_*", light))
+		assert.Error(t, router.Handle("eth_get*By?ash", light))
+	})
+}
+
+func TestRouter_Call(t *testing.T) {
+	router := NewRouter(RouterConfig{})
+	require.NoError(t, router.Handle("eth_*", &fakeUpstream{name: "eth"}))
+
+	resp, err := router.Call(context.Background(), NewRequestWithID("eth_chainId", nil, 1))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"eth"}, resultNames(t, []*Response{resp}))
+
+	resp, err = router.Call(context.Background(), NewRequestWithID("net_version", nil, 2))
+	require.NoError(t, err)
+	assert.Equal(t, "2", resp.IDString())
+	assert.Equal(t, MethodNotFound, resp.Err().Code)
+
+	resp, err = router.Call(context.Background(), NewNotification("net_version", nil))
+	require.NoError(t, err)
+	assert.Nil(t, resp)
+
+	_, err = router.Call(context.Background(), nil)
+	assert.Error(t, err)
+}
+
+func TestRouter_CallBatch(t *testing.T) {
+	archive := &fakeUpstream{name: "archive"}
+	light := &fakeUpstream{name: "light"}
+	router := NewRouter(RouterConfig{})
+	require.NoError(t, router.Handle("debug_*", archive))
+	require.NoError(t, router.Handle("eth_blockNumber", light))
+
+	reqs := []*Request{
+		NewRequestWithID("debug_a", nil, 1),
+		NewRequestWithID("eth_blockNumber", nil, 2),
+		NewNotification("debug_notify", nil),
+		NewRequestWithID("net_version", nil, 3),
+		NewRequestWithID("debug_b", nil, 4),
+	}
+	resps, err := router.CallBatch(context.Background(), reqs)
+	require.NoError(t, err)
+
+	require.Len(t, resps, 4)
+	for i, id := range []string{"1", "2", "3", "4"} {
+		assert.Equal(t, id, resps[i].IDString())
+	}
+	assert.Equal(t, []string{"archive", "light", msgMethodNotFound, "archive"},
+		resultNames(t, resps))
+	assert.Equal(t, [][]string{{"debug_a", "debug_notify", "debug_b"}}, archive.batchMethods())
+	assert.Equal(t, [][]string{{"eth_blockNumber"}}, light.batchMethods())
+
+	_, err = EncodeBatchResponse(resps)
+	assert.NoError(t, err)
+}
+
+func TestRouter_CallBatchFailures(t *testing.T) {
+	down := &fakeUpstream{name: "down", err: errors.New("down")}
+	up := &fakeUpstream{name: "up"}
+
+	t.Run("Partial failure", func(t *testing.T) {
+		var reported []error
+		router := NewRouter(RouterConfig{
+			ErrorHandler: func(err error) { reported = append(reported, err) },
+		})
+		require.NoError(t, router.Handle("a", down))
+		require.NoError(t, router.Handle("b", up))
+
+		resps, err := router.CallBatch(context.Background(), []*Request{
+			NewRequestWithID("a", nil, 1),
+			NewRequestWithID("b", nil, 2),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{msgUpstreamError, "up"}, resultNames(t, resps))
+		require.Len(t, reported, 1)
+		assert.EqualError(t, reported[0], "down")
+	})
+
+	t.Run("Total failure", func(t *testing.T) {
+		router := NewRouter(RouterConfig{Default: down})
+		_, err := router.CallBatch(context.Background(), []*Request{NewRequestWithID("a", nil, 1)})
+		assert.EqualError(t, err, "down")
+	})
+
+	t.Run("Missing responses", func(t *testing.T) {
+		router := NewRouter(RouterConfig{Default: &fakeUpstream{silent: true}})
+		resps, err := router.CallBatch(context.Background(), []*Request{NewRequestWithID("a", nil, 1)})
+		require.NoError(t, err)
+		assert.Equal(t, []string{msgUpstreamError}, resultNames(t, resps))
+	})
+}
+
+func TestRouter_ReverseProxy(t *testing.T) {
+	router := NewRouter(RouterConfig{})
+	require.NoError(t, router.Handle("debug_*", &fakeUpstream{name: "archive"}))
+	require.NoError(t, router.Handle("eth_*", &fakeUpstream{name: "light"}))
+	proxy, err := NewReverseProxy(ReverseProxyConfig{Upstream: router})
+	require.NoError(t, err)
+
+	rec := serveProxy(proxy, `[
+		{"jsonrpc":"2.0","id":"a","method":"eth_blockNumber"},
+		{"jsonrpc":"2.0","id":"b","method":"debug_traceBlock"},
+		{"jsonrpc":"2.0","id":"c","method":"eth_chainId"}
+	]`)
+
+	resps, err := DecodeBatchResponse(rec.Body.Bytes())
+	require.NoError(t, err)
+	require.Len(t, resps, 3)
+	assert.Equal(t, []string{"light", "archive", "light"}, resultNames(t, resps))
+	for i, id := range []string{"a", "b", "c"} {
+		assert.Equal(t, id, resps[i].IDString())
+	}
+}
@@ -0,0 +1,212 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultMaxResponseSize is the default limit on the size of upstream response bodies.
+	DefaultMaxResponseSize = 32 << 20 // 32 MiB
+
+	contentTypeJSON = "application/json"
+)
+
+// Upstream sends requests to a remote JSON-RPC endpoint.
+//
+// Implementations return an error only if no JSON-RPC response could be obtained, e.g. due to a
+// transport failure. JSON-RPC error responses are returned as responses.
+type Upstream interface {
+	// Call sends a single request and returns its response, or nil for a notification. Call has
+	// the signature of a HandlerFunc.
+	Call(ctx context.Context, req *Request) (*Response, error)
+
+	// CallBatch sends the requests as a single batch and returns the responses in the order
+	// they were received, which may differ from the order of the requests. Notifications have
+	// no response.
+	CallBatch(ctx context.Context, reqs []*Request) ([]*Response, error)
+}
+
+// HTTPStatusError is returned by HTTPUpstream when the upstream responds with a non-2xx status.
+type HTTPStatusError struct {
+	StatusCode int
+	Status     string
+
+	// RetryAfter is the delay requested by the Retry-After header, or zero if there is none.
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface.
+func (e *HTTPStatusError) Error() string {
+	return "upstream returned status " + e.Status
+}
+
+// HTTPUpstreamConfig configures an HTTPUpstream.
+type HTTPUpstreamConfig struct {
+	// URL is the endpoint requests are posted to. Required.
+	URL string
+
+	// Client sends the HTTP requests. Defaults to http.DefaultClient.
+	Client *http.Client
+
+	// Header holds additional headers sent with every request, e.g. for authentication.
+	Header http.Header
+
+	// MaxResponseSize limits the size of response bodies in bytes. Defaults to
+	// DefaultMaxResponseSize.
+	MaxResponseSize int64
+}
+
+// HTTPUpstream is an Upstream that posts requests to a JSON-RPC endpoint over HTTP. A response
+// with a non-2xx status results in an *HTTPStatusError, and a body that cannot be decoded as
+// JSON-RPC responses results in an error as well.
+//
+// An HTTPUpstream is safe for concurrent use.
+type HTTPUpstream struct {
+	url             string
+	client          *http.Client
+	header          http.Header
+	maxResponseSize int64
+}
+
+// NewHTTPUpstream creates an HTTPUpstream with the given configuration.
+func NewHTTPUpstream(config HTTPUpstreamConfig) (*HTTPUpstream, error) {
+	parsed, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid upstream url: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("invalid upstream url %q: scheme must be http or https", config.URL)
+	}
+
+	upstream := &HTTPUpstream{
+		url:             config.URL,
+		client:          config.Client,
+		header:          config.Header.Clone(),
+		maxResponseSize: config.MaxResponseSize,
+	}
+	if upstream.client == nil {
+		upstream.client = http.DefaultClient
+	}
+	if upstream.maxResponseSize <= 0 {
+		upstream.maxResponseSize = DefaultMaxResponseSize
+	}
+
+	return upstream, nil
+}
+
+// URL returns the endpoint of the upstream.
+func (u *HTTPUpstream) URL() string {
+	return u.url
+}
+
+// Call posts a single request and returns its response, or nil for a notification.
+func (u *HTTPUpstream) Call(ctx context.Context, req *Request) (*Response, error) {
+	if req == nil {
+		return nil, errors.New("request cannot be nil")
+	}
+
+	body, err := req.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	data, err := u.post(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	if req.IsNotification() {
+		return nil, nil
+	}
+
+	resp, err := DecodeResponse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode upstream response: %w", err)
+	}
+	return resp, nil
+}
+
+// CallBatch posts the requests as a single batch and returns the responses. A batch answered with
+// a single response, e.g. an error response to an unparsable batch, yields that response alone.
+func (u *HTTPUpstream) CallBatch(ctx context.Context, reqs []*Request) ([]*Response, error) {
+	body, err := EncodeBatchRequest(reqs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode batch request: %w", err)
+	}
+
+	data, err := u.post(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		// Batches of notifications have no response
+		return nil, nil
+	}
+
+	resps, _, err := DecodeResponseOrBatch(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode upstream response: %w", err)
+	}
+	return resps, nil
+}
+
+// post sends body to the upstream and returns the response body.
+func (u *HTTPUpstream) post(ctx context.Context, body []byte) ([]byte, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, u.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create upstream request: %w", err)
+	}
+	for key, values := range u.header {
+		httpReq.Header[key] = values
+	}
+	httpReq.Header.Set("Content-Type", contentTypeJSON)
+	httpReq.Header.Set("Accept", contentTypeJSON)
+
+	httpResp, err := u.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = httpResp.Body.Close() }()
+
+	if httpResp.StatusCode < http.StatusOK || httpResp.StatusCode >= http.StatusMultipleChoices {
+		// Drain a bounded amount of the body so the connection can be reused
+		_, _ = io.Copy(io.Discard, io.LimitReader(httpResp.Body, u.maxResponseSize))
+		return nil, &HTTPStatusError{
+			StatusCode: httpResp.StatusCode,
+			Status:     httpResp.Status,
+			RetryAfter: parseRetryAfter(httpResp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(httpResp.Body, u.maxResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upstream response: %w", err)
+	}
+	if int64(len(data)) > u.maxResponseSize {
+		return nil, fmt.Errorf("upstream response exceeds %d bytes", u.maxResponseSize)
+	}
+
+	return data, nil
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which is either a number of seconds or
+// an HTTP date. Returns zero if the value is missing, invalid or in the past.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+	return 0
+}
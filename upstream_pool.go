@@ -0,0 +1,537 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultProbeInterval is the default interval between health checks of pool endpoints.
+	DefaultProbeInterval = 10 * time.Second
+
+	// DefaultProbeTimeout is the default timeout of a single health check.
+	DefaultProbeTimeout = 5 * time.Second
+
+	// DefaultUnhealthyThreshold is the default number of consecutive failed calls after which an
+	// endpoint is considered unhealthy.
+	DefaultUnhealthyThreshold = 3
+
+	// statsSmoothing is the weight of a new sample in the moving averages of endpoint stats.
+	statsSmoothing = 0.2
+)
+
+// BalancingStrategy selects the endpoint of an UpstreamPool that a call is sent to.
+type BalancingStrategy int
+
+const (
+	// BalanceRoundRobin cycles through the endpoints in order.
+	BalanceRoundRobin BalancingStrategy = iota
+
+	// BalanceLeastLatency picks the endpoint with the lowest average latency. Endpoints without
+	// latency samples are picked first.
+	BalanceLeastLatency
+
+	// BalanceWeighted picks endpoints at random, proportionally to their weight.
+	BalanceWeighted
+)
+
+// PoolEndpoint is an endpoint of an UpstreamPool.
+type PoolEndpoint struct {
+	// Name identifies the endpoint in stats, e.g. its URL.
+	Name string
+
+	// Upstream sends the requests to the endpoint. Required.
+	Upstream Upstream
+
+	// Weight is the relative share of calls the endpoint receives with BalanceWeighted. Defaults
+	// to 1.
+	Weight int
+}
+
+// UpstreamPoolConfig configures an UpstreamPool.
+type UpstreamPoolConfig struct {
+	// Endpoints are the equivalent endpoints of the pool. At least one is required.
+	Endpoints []PoolEndpoint
+
+	// Strategy selects the endpoint of each call. Defaults to BalanceRoundRobin.
+	Strategy BalancingStrategy
+
+	// ProbeRequest, if set, is sent to every endpoint each ProbeInterval to check its health. An
+	// endpoint is healthy if it answers the probe with a response that is not an error response.
+	// Without a ProbeRequest, unhealthy endpoints are tried again once ProbeInterval has passed.
+	ProbeRequest *Request
+
+	// ProbeInterval is the interval between health checks. Defaults to DefaultProbeInterval.
+	ProbeInterval time.Duration
+
+	// ProbeTimeout is the timeout of a single health check. Defaults to DefaultProbeTimeout.
+	ProbeTimeout time.Duration
+
+	// UnhealthyThreshold is the number of consecutive failed calls after which an endpoint is
+	// considered unhealthy. Defaults to DefaultUnhealthyThreshold.
+	UnhealthyThreshold int
+
+	// FailoverCodes are the JSON-RPC error codes that make a call fail over to the next endpoint,
+	// in addition to transport errors. Responses with other error codes are returned as-is.
+	FailoverCodes []int
+
+	// MaxAttempts limits the number of endpoints a call is sent to. Defaults to the number of
+	// endpoints.
+	MaxAttempts int
+}
+
+// EndpointStats holds the health and performance of a pool endpoint.
+type EndpointStats struct {
+	Name    string
+	Healthy bool
+
+	// Latency is the moving average of the latency of calls and probes.
+	Latency time.Duration
+
+	// ErrorRate is the moving average of the share of failed calls and probes, from 0 to 1.
+	ErrorRate float64
+
+	Requests uint64
+	Failures uint64
+}
+
+// UpstreamPool is an Upstream that spreads calls across equivalent endpoints. It tracks the
+// latency and error rate of every endpoint, optionally health-checks them with a probe request,
+// and fails over to the next endpoint when a call fails with a transport error or with one of the
+// configured JSON-RPC error codes. Unhealthy endpoints are skipped as long as a healthy endpoint
+// is left.
+//
+// An UpstreamPool is safe for concurrent use. Close stops the health checks.
+//
+// Example usage:
+//
+//	pool, err := jsonrpc.NewUpstreamPool(jsonrpc.UpstreamPoolConfig{
+//		Endpoints:     []jsonrpc.PoolEndpoint{{Name: "a", Upstream: a}, {Name: "b", Upstream: b}},
+//		Strategy:      jsonrpc.BalanceLeastLatency,
+//		ProbeRequest:  jsonrpc.NewRequest("eth_chainId", nil),
+//		FailoverCodes: []int{-32005},
+//	})
+//	defer pool.Close()
+//	resp, err := pool.Call(ctx, req)
+type UpstreamPool struct {
+	endpoints   []*poolEndpoint
+	strategy    BalancingStrategy
+	probe       *Request
+	interval    time.Duration
+	timeout     time.Duration
+	threshold   int
+	failover    []int
+	maxAttempts int
+
+	next atomic.Uint64 // Round-robin position
+	now  func() time.Time
+
+	stop      chan struct{}
+	stopOnce  sync.Once
+	probeDone chan struct{}
+}
+
+// poolEndpoint is an endpoint with its stats.
+type poolEndpoint struct {
+	name     string
+	upstream Upstream
+	weight   int
+
+	mutex          sync.Mutex
+	healthy        bool
+	unhealthySince time.Time
+	consecutive    int // Consecutive failures
+	latency        float64
+	errorRate      float64
+	requests       uint64
+	failures       uint64
+}
+
+// NewUpstreamPool creates an UpstreamPool with the given configuration. If a ProbeRequest is
+// configured, health checks start immediately and run until Close is called.
+func NewUpstreamPool(config UpstreamPoolConfig) (*UpstreamPool, error) {
+	if len(config.Endpoints) == 0 {
+		return nil, errors.New("pool requires at least one endpoint")
+	}
+
+	pool := &UpstreamPool{
+		strategy:    config.Strategy,
+		probe:       config.ProbeRequest,
+		interval:    positiveOr(config.ProbeInterval, DefaultProbeInterval),
+		timeout:     positiveOr(config.ProbeTimeout, DefaultProbeTimeout),
+		threshold:   positiveOr(config.UnhealthyThreshold, DefaultUnhealthyThreshold),
+		failover:    slices.Clone(config.FailoverCodes),
+		maxAttempts: positiveOr(config.MaxAttempts, len(config.Endpoints)),
+		now:         time.Now,
+		stop:        make(chan struct{}),
+		probeDone:   make(chan struct{}),
+	}
+	for _, endpoint := range config.Endpoints {
+		if endpoint.Upstream == nil {
+			return nil, errors.New("pool endpoint upstream cannot be nil")
+		}
+		pool.endpoints = append(pool.endpoints, &poolEndpoint{
+			name:     endpoint.Name,
+			upstream: endpoint.Upstream,
+			weight:   positiveOr(endpoint.Weight, 1),
+			healthy:  true,
+		})
+	}
+
+	if pool.probe != nil {
+		go pool.runProbes()
+	} else {
+		close(pool.probeDone)
+	}
+
+	return pool, nil
+}
+
+// Close stops the health checks and waits for a running check to finish.
+func (p *UpstreamPool) Close() error {
+	p.stopOnce.Do(func() { close(p.stop) })
+	<-p.probeDone
+	return nil
+}
+
+// Call sends a single request to an endpoint, failing over to the next endpoint on a transport
+// error or a failover error code. If all attempts fail, the outcome of the last attempt is
+// returned.
+func (p *UpstreamPool) Call(ctx context.Context, req *Request) (*Response, error) {
+	if req == nil {
+		return nil, errors.New("request cannot be nil")
+	}
+
+	var resp *Response
+	var err error
+	tried := make([]bool, len(p.endpoints))
+	for attempt := 0; attempt < p.maxAttempts; attempt++ {
+		endpoint := p.pick(tried)
+		if endpoint == nil {
+			break
+		}
+
+		start := p.now()
+		resp, err = endpoint.upstream.Call(ctx, req)
+		if ctx.Err() != nil {
+			// The caller gave up, which says nothing about the endpoint
+			break
+		}
+		failed := err != nil || p.isFailover(resp)
+		p.record(endpoint, p.now().Sub(start), failed, err == nil)
+
+		if !failed {
+			break
+		}
+	}
+
+	return resp, err
+}
+
+// CallBatch sends the requests as a batch to an endpoint. On a transport error the whole batch
+// fails over to the next endpoint, and requests answered with a failover error code are sent to
+// the next endpoint as a smaller batch. The responses are returned in the order of the requests,
+// whose IDs must be unique within the batch.
+func (p *UpstreamPool) CallBatch(ctx context.Context, reqs []*Request) ([]*Response, error) {
+	results := make(map[ID]*Response, len(reqs))
+	pending := reqs
+	var err error
+	delivered := false
+
+	tried := make([]bool, len(p.endpoints))
+	for attempt := 0; attempt < p.maxAttempts && len(pending) > 0; attempt++ {
+		endpoint := p.pick(tried)
+		if endpoint == nil {
+			break
+		}
+
+		var resps []*Response
+		start := p.now()
+		resps, err = endpoint.upstream.CallBatch(ctx, pending)
+		if ctx.Err() != nil {
+			// The caller gave up, which says nothing about the endpoint
+			break
+		}
+		if err != nil {
+			p.record(endpoint, p.now().Sub(start), true, false)
+			continue
+		}
+		delivered = true
+
+		failed := false
+		retry := make(map[ID]bool)
+		for _, resp := range resps {
+			results[resp.ID()] = resp
+			if p.isFailover(resp) {
+				failed = true
+				retry[resp.ID()] = true
+			}
+		}
+		p.record(endpoint, p.now().Sub(start), failed, true)
+
+		pending = slices.DeleteFunc(slices.Clone(pending), func(req *Request) bool {
+			return !retry[req.ID]
+		})
+	}
+
+	if !delivered && err != nil {
+		return nil, err
+	}
+	return orderResponses(reqs, results), nil
+}
+
+// CheckHealth probes all endpoints concurrently and updates their health. It is called
+// periodically if a ProbeRequest is configured, and does nothing otherwise.
+func (p *UpstreamPool) CheckHealth(ctx context.Context) {
+	if p.probe == nil {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, endpoint := range p.endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			probeCtx, cancel := context.WithTimeout(ctx, p.timeout)
+			defer cancel()
+
+			start := p.now()
+			resp, err := endpoint.upstream.Call(probeCtx, p.probe)
+			failed := err != nil || resp == nil || resp.Err() != nil
+			p.recordProbe(endpoint, p.now().Sub(start), failed)
+		}()
+	}
+	wg.Wait()
+}
+
+// Stats returns the stats of the endpoints, in the order they were configured.
+func (p *UpstreamPool) Stats() []EndpointStats {
+	stats := make([]EndpointStats, 0, len(p.endpoints))
+	for _, endpoint := range p.endpoints {
+		endpoint.mutex.Lock()
+		stats = append(stats, EndpointStats{
+			Name:      endpoint.name,
+			Healthy:   endpoint.healthy,
+			Latency:   time.Duration(endpoint.latency),
+			ErrorRate: endpoint.errorRate,
+			Requests:  endpoint.requests,
+			Failures:  endpoint.failures,
+		})
+		endpoint.mutex.Unlock()
+	}
+	return stats
+}
+
+// runProbes runs health checks every interval until the pool is closed.
+func (p *UpstreamPool) runProbes() {
+	defer close(p.probeDone)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-p.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.CheckHealth(ctx)
+		select {
+		case <-ticker.C:
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// pick returns the next endpoint to try according to the strategy, preferring healthy endpoints,
+// and marks it as tried. Returns nil if all endpoints have been tried.
+func (p *UpstreamPool) pick(tried []bool) *poolEndpoint {
+	candidates := make([]int, 0, len(p.endpoints))
+	for i, endpoint := range p.endpoints {
+		if !tried[i] && p.isAvailable(endpoint) {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		// No healthy endpoint is left, so try the unhealthy ones rather than failing
+		for i := range p.endpoints {
+			if !tried[i] {
+				candidates = append(candidates, i)
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var index int
+	switch p.strategy {
+	case BalanceLeastLatency:
+		index = slices.MinFunc(candidates, func(a, b int) int {
+			return compareLatency(p.endpoints[a], p.endpoints[b])
+		})
+	case BalanceWeighted:
+		index = p.pickWeighted(candidates)
+	default:
+		start := int(p.next.Add(1)-1) % len(p.endpoints)
+		index = candidates[0]
+		for _, candidate := range candidates {
+			if candidate >= start {
+				index = candidate
+				break
+			}
+		}
+	}
+
+	tried[index] = true
+	return p.endpoints[index]
+}
+
+// pickWeighted returns one of the candidates at random, proportionally to their weight.
+func (p *UpstreamPool) pickWeighted(candidates []int) int {
+	total := 0
+	for _, candidate := range candidates {
+		total += p.endpoints[candidate].weight
+	}
+
+	n := rand.IntN(total)
+	for _, candidate := range candidates {
+		n -= p.endpoints[candidate].weight
+		if n < 0 {
+			return candidate
+		}
+	}
+	return candidates[len(candidates)-1]
+}
+
+// isAvailable returns true if calls may be sent to the endpoint: if it is healthy, or if no
+// probe is configured and it has been unhealthy for at least the probe interval.
+func (p *UpstreamPool) isAvailable(endpoint *poolEndpoint) bool {
+	endpoint.mutex.Lock()
+	defer endpoint.mutex.Unlock()
+
+	if endpoint.healthy {
+		return true
+	}
+	return p.probe == nil && p.now().Sub(endpoint.unhealthySince) >= p.interval
+}
+
+// isFailover returns true if resp is an error response with one of the failover codes.
+func (p *UpstreamPool) isFailover(resp *Response) bool {
+	if resp == nil || len(p.failover) == 0 {
+		return false
+	}
+	rpcErr := resp.Err()
+	return rpcErr != nil && slices.Contains(p.failover, rpcErr.Code)
+}
+
+// record updates the stats of the endpoint with the outcome of a call. The latency is only
+// sampled if the endpoint responded.
+func (p *UpstreamPool) record(
+	endpoint *poolEndpoint,
+	latency time.Duration,
+	failed, responded bool,
+) {
+	endpoint.mutex.Lock()
+	defer endpoint.mutex.Unlock()
+
+	endpoint.requests++
+	endpoint.sample(latency, failed, responded)
+	if !failed {
+		endpoint.consecutive = 0
+		endpoint.healthy = true
+		return
+	}
+
+	endpoint.failures++
+	endpoint.consecutive++
+	if endpoint.consecutive >= p.threshold && endpoint.healthy {
+		endpoint.healthy = false
+		endpoint.unhealthySince = p.now()
+	}
+}
+
+// recordProbe updates the stats and health of the endpoint with the outcome of a probe.
+func (p *UpstreamPool) recordProbe(endpoint *poolEndpoint, latency time.Duration, failed bool) {
+	endpoint.mutex.Lock()
+	defer endpoint.mutex.Unlock()
+
+	endpoint.sample(latency, failed, !failed)
+	if failed {
+		if endpoint.healthy {
+			endpoint.healthy = false
+			endpoint.unhealthySince = p.now()
+		}
+		return
+	}
+	endpoint.healthy = true
+	endpoint.consecutive = 0
+}
+
+// sample adds an outcome to the moving averages. The caller must hold the mutex.
+func (e *poolEndpoint) sample(latency time.Duration, failed, responded bool) {
+	outcome := 0.0
+	if failed {
+		outcome = 1
+	}
+	e.errorRate += statsSmoothing * (outcome - e.errorRate)
+
+	if !responded {
+		return
+	}
+	if e.latency == 0 {
+		e.latency = float64(latency)
+	} else {
+		e.latency += statsSmoothing * (float64(latency) - e.latency)
+	}
+}
+
+// orderResponses returns the responses to the requests in the order of the requests, skipping
+// requests without a response.
+func orderResponses(reqs []*Request, byID map[ID]*Response) []*Response {
+	out := make([]*Response, 0, len(reqs))
+	for _, req := range reqs {
+		if resp, ok := byID[req.ID]; ok && !req.IsNotification() {
+			out = append(out, resp)
+		}
+	}
+	return out
+}
+
+// compareLatency orders endpoints by ascending average latency.
+func compareLatency(a, b *poolEndpoint) int {
+	a.mutex.Lock()
+	latencyA := a.latency
+	a.mutex.Unlock()
+	b.mutex.Lock()
+	latencyB := b.latency
+	b.mutex.Unlock()
+
+	switch {
+	case latencyA < latencyB:
+		return -1
+	case latencyA > latencyB:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// positiveOr returns value if it is positive, and fallback otherwise.
+func positiveOr[T int | time.Duration](value, fallback T) T {
+	if value > 0 {
+		return value
+	}
+	return fallback
+}
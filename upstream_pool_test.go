@@ -0,0 +1,270 @@
+package jsonrpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPool creates a pool of the upstreams and closes it when the test ends.
+func newTestPool(
+	t *testing.T,
+	config UpstreamPoolConfig,
+	upstreams ...*fakeUpstream,
+) *UpstreamPool {
+	t.Helper()
+
+	for _, upstream := range upstreams {
+		config.Endpoints = append(config.Endpoints,
+			PoolEndpoint{Name: upstream.name, Upstream: upstream})
+	}
+	pool, err := NewUpstreamPool(config)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = pool.Close() })
+
+	return pool
+}
+
+// callName calls pool with a new request and returns the string result or error message.
+func callName(t *testing.T, pool *UpstreamPool) string {
+	t.Helper()
+
+	resp, err := pool.Call(context.Background(), NewRequest("m", nil))
+	require.NoError(t, err)
+	return resultNames(t, []*Response{resp})[0]
+}
+
+func TestNewUpstreamPool(t *testing.T) {
+	_, err := NewUpstreamPool(UpstreamPoolConfig{})
+	assert.Error(t, err)
+	_, err = NewUpstreamPool(UpstreamPoolConfig{Endpoints: []PoolEndpoint{{Name: "a"}}})
+	assert.Error(t, err)
+
+	pool := newTestPool(t, UpstreamPoolConfig{}, &fakeUpstream{name: "a"})
+	assert.Equal(t, DefaultProbeInterval, pool.interval)
+	assert.Equal(t, DefaultProbeTimeout, pool.timeout)
+	assert.Equal(t, DefaultUnhealthyThreshold, pool.threshold)
+	assert.Equal(t, 1, pool.maxAttempts)
+}
+
+func TestUpstreamPool_Strategies(t *testing.T) {
+	t.Run("Round robin", func(t *testing.T) {
+		pool := newTestPool(t, UpstreamPoolConfig{},
+			&fakeUpstream{name: "a"}, &fakeUpstream{name: "b"}, &fakeUpstream{name: "c"})
+
+		var names []string
+		for range 6 {
+			names = append(names, callName(t, pool))
+		}
+		assert.Equal(t, []string{"a", "b", "c", "a", "b", "c"}, names)
+	})
+
+	t.Run("Least latency", func(t *testing.T) {
+		pool := newTestPool(t, UpstreamPoolConfig{Strategy: BalanceLeastLatency},
+			&fakeUpstream{name: "slow"}, &fakeUpstream{name: "fast"})
+		pool.record(pool.endpoints[0], 50*time.Millisecond, false, true)
+		pool.record(pool.endpoints[1], 5*time.Millisecond, false, true)
+
+		assert.Equal(t, "fast", callName(t, pool))
+		stats := pool.Stats()
+		assert.Equal(t, 50*time.Millisecond, stats[0].Latency)
+	})
+
+	t.Run("Weighted", func(t *testing.T) {
+		heavy := &fakeUpstream{name: "heavy"}
+		light := &fakeUpstream{name: "light"}
+		pool, err := NewUpstreamPool(UpstreamPoolConfig{
+			Strategy: BalanceWeighted,
+			Endpoints: []PoolEndpoint{
+				{Name: "heavy", Upstream: heavy, Weight: 9},
+				{Name: "light", Upstream: light},
+			},
+		})
+		require.NoError(t, err)
+
+		for range 1000 {
+			callName(t, pool)
+		}
+		assert.Greater(t, heavy.calls.Load(), 4*light.calls.Load())
+		assert.Positive(t, light.calls.Load())
+	})
+}
+
+func TestUpstreamPool_Failover(t *testing.T) {
+	t.Run("Transport error", func(t *testing.T) {
+		a, b := &fakeUpstream{name: "a"}, &fakeUpstream{name: "b"}
+		a.setErr(errors.New("connection refused"))
+		pool := newTestPool(t, UpstreamPoolConfig{}, a, b)
+
+		assert.Equal(t, "b", callName(t, pool))
+		stats := pool.Stats()
+		assert.Equal(t, uint64(1), stats[0].Failures)
+		assert.InDelta(t, statsSmoothing, stats[0].ErrorRate, 1e-9)
+		assert.Zero(t, stats[1].Failures)
+	})
+
+	t.Run("Failover codes", func(t *testing.T) {
+		a, b := &fakeUpstream{name: "a"}, &fakeUpstream{name: "b"}
+		a.code = -32005
+		pool := newTestPool(t, UpstreamPoolConfig{FailoverCodes: []int{-32005}}, a, b)
+		assert.Equal(t, "b", callName(t, pool))
+
+		pool = newTestPool(t, UpstreamPoolConfig{}, a, b)
+		assert.Equal(t, "a", callName(t, pool), "other error responses are returned as-is")
+	})
+
+	t.Run("All attempts fail", func(t *testing.T) {
+		a, b := &fakeUpstream{name: "a"}, &fakeUpstream{name: "b"}
+		a.setErr(errors.New("a down"))
+		b.setErr(errors.New("b down"))
+		pool := newTestPool(t, UpstreamPoolConfig{}, a, b)
+
+		_, err := pool.Call(context.Background(), NewRequest("m", nil))
+		assert.EqualError(t, err, "b down")
+		assert.Equal(t, int32(1), a.calls.Load())
+		assert.Equal(t, int32(1), b.calls.Load())
+	})
+
+	t.Run("Max attempts", func(t *testing.T) {
+		a, b := &fakeUpstream{name: "a"}, &fakeUpstream{name: "b"}
+		a.setErr(errors.New("a down"))
+		pool := newTestPool(t, UpstreamPoolConfig{MaxAttempts: 1}, a, b)
+
+		_, err := pool.Call(context.Background(), NewRequest("m", nil))
+		assert.EqualError(t, err, "a down")
+		assert.Zero(t, b.calls.Load())
+	})
+}
+
+func TestUpstreamPool_Health(t *testing.T) {
+	t.Run("Unhealthy after consecutive failures", func(t *testing.T) {
+		a, b := &fakeUpstream{name: "a"}, &fakeUpstream{name: "b"}
+		a.setErr(errors.New("down"))
+		now := time.Unix(0, 0)
+		pool := newTestPool(t, UpstreamPoolConfig{UnhealthyThreshold: 2, ProbeInterval: time.Minute},
+			a, b)
+		pool.now = func() time.Time { return now }
+
+		for range 4 {
+			assert.Equal(t, "b", callName(t, pool))
+		}
+		assert.False(t, pool.Stats()[0].Healthy)
+		assert.Equal(t, int32(2), a.calls.Load(), "unhealthy endpoint should be skipped")
+
+		// Without probes, the endpoint is tried again after the probe interval
+		a.setErr(nil)
+		now = now.Add(time.Minute)
+		names := []string{callName(t, pool), callName(t, pool)}
+		assert.Contains(t, names, "a")
+		assert.True(t, pool.Stats()[0].Healthy)
+	})
+
+	t.Run("Unhealthy endpoints are used when none is healthy", func(t *testing.T) {
+		a := &fakeUpstream{name: "a"}
+		pool := newTestPool(t, UpstreamPoolConfig{UnhealthyThreshold: 1}, a)
+		a.setErr(errors.New("down"))
+		_, err := pool.Call(context.Background(), NewRequest("m", nil))
+		require.Error(t, err)
+		require.False(t, pool.Stats()[0].Healthy)
+
+		a.setErr(nil)
+		assert.Equal(t, "a", callName(t, pool))
+	})
+
+	t.Run("Probes", func(t *testing.T) {
+		a, b := &fakeUpstream{name: "a"}, &fakeUpstream{name: "b"}
+		a.setErr(errors.New("down"))
+		pool := newTestPool(t, UpstreamPoolConfig{
+			ProbeRequest:  NewRequest("eth_chainId", nil),
+			ProbeInterval: time.Hour,
+		}, a, b)
+
+		require.Eventually(t, func() bool {
+			return !pool.Stats()[0].Healthy
+		}, time.Second, time.Millisecond, "initial probe should mark the endpoint unhealthy")
+		assert.True(t, pool.Stats()[1].Healthy)
+		assert.Equal(t, "b", callName(t, pool))
+		assert.Equal(t, "b", callName(t, pool))
+
+		a.setErr(nil)
+		pool.CheckHealth(context.Background())
+		assert.True(t, pool.Stats()[0].Healthy)
+
+		b.code = ServerSideException
+		pool.CheckHealth(context.Background())
+		assert.False(t, pool.Stats()[1].Healthy, "error responses should fail the probe")
+
+		require.NoError(t, pool.Close())
+		require.NoError(t, pool.Close())
+	})
+}
+
+func TestUpstreamPool_CallBatch(t *testing.T) {
+	t.Run("Failover of failed requests", func(t *testing.T) {
+		a, b := &fakeUpstream{name: "a"}, &fakeUpstream{name: "b"}
+		a.code = -32005
+		a.codeMethods = []string{"limited"}
+		pool := newTestPool(t, UpstreamPoolConfig{FailoverCodes: []int{-32005}}, a, b)
+
+		resps, err := pool.CallBatch(context.Background(), []*Request{
+			NewRequestWithID("ok", nil, 1),
+			NewRequestWithID("limited", nil, 2),
+			NewNotification("notify", nil),
+			NewRequestWithID("ok", nil, 3),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "a"}, resultNames(t, resps))
+		assert.Equal(t, [][]string{{"limited"}}, b.batchMethods(),
+			"only the failed request should be resent")
+	})
+
+	t.Run("Transport error", func(t *testing.T) {
+		a, b := &fakeUpstream{name: "a"}, &fakeUpstream{name: "b"}
+		a.setErr(errors.New("down"))
+		pool := newTestPool(t, UpstreamPoolConfig{}, a, b)
+
+		resps, err := pool.CallBatch(context.Background(), []*Request{
+			NewRequestWithID("m", nil, 1),
+			NewRequestWithID("m", nil, 2),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b", "b"}, resultNames(t, resps))
+
+		b.setErr(errors.New("b down"))
+		_, err = pool.CallBatch(context.Background(), []*Request{NewRequestWithID("m", nil, 1)})
+		assert.EqualError(t, err, "b down")
+	})
+	t.Run("Notifications after failover", func(t *testing.T) {
+		a, b := &fakeUpstream{name: "a"}, &fakeUpstream{name: "b"}
+		a.setErr(errors.New("down"))
+		pool := newTestPool(t, UpstreamPoolConfig{}, a, b)
+
+		resps, err := pool.CallBatch(context.Background(), []*Request{NewNotification("n", nil)})
+		require.NoError(t, err, "the batch was delivered by the second endpoint")
+		assert.Empty(t, resps)
+		assert.Equal(t, []int{1}, b.batchSizes())
+	})
+}
+
+func TestUpstreamPool_Cancellation(t *testing.T) {
+	upstream := &fakeUpstream{name: "a", delay: 50 * time.Millisecond}
+	pool := newTestPool(t, UpstreamPoolConfig{}, upstream)
+
+	for range DefaultUnhealthyThreshold {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		_, err := pool.Call(ctx, NewRequest("m", nil))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		_, err = pool.CallBatch(ctx, []*Request{NewRequestWithID("m", nil, 1)})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		cancel()
+	}
+
+	stats := pool.Stats()[0]
+	assert.True(t, stats.Healthy, "canceled calls should not eject the endpoint")
+	assert.Zero(t, stats.Failures)
+	assert.Zero(t, stats.ErrorRate)
+}
@@ -0,0 +1,328 @@
+package jsonrpc
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUpstream is a configurable Upstream for tests. By default it answers each request with its
+// name as the result, and returns batch responses in reverse order. It records the batches it
+// receives and counts its calls.
+type fakeUpstream struct {
+	name string
+
+	// delay is waited for before answering. Calls whose context is done first count as canceled.
+	delay time.Duration
+
+	// code, if set, makes requests for codeMethods (all methods if empty) be answered with an
+	// error response with that code and the name as message.
+	code        int
+	codeMethods []string
+
+	// id, if set, is the ID of all responses instead of the ID of the request.
+	id any
+
+	// echoMethod makes the result of each response the method of the request instead of the name.
+	echoMethod bool
+
+	// silent makes the upstream answer no request.
+	silent bool
+
+	// respond, if set, answers the requests of each call instead.
+	respond func(reqs []*Request) ([]*Response, error)
+
+	calls    atomic.Int32 // Calls of Call and CallBatch
+	canceled atomic.Int32
+
+	mutex   sync.Mutex
+	err     error // Returned by every call if set, see setErr
+	batches [][]*Request
+}
+
+func (u *fakeUpstream) setErr(err error) {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.err = err
+}
+
+func (u *fakeUpstream) Call(ctx context.Context, req *Request) (*Response, error) {
+	resps, err := u.answer(ctx, []*Request{req})
+	if err != nil || len(resps) == 0 {
+		return nil, err
+	}
+	return resps[0], nil
+}
+
+func (u *fakeUpstream) CallBatch(ctx context.Context, reqs []*Request) ([]*Response, error) {
+	u.mutex.Lock()
+	u.batches = append(u.batches, reqs)
+	u.mutex.Unlock()
+
+	return u.answer(ctx, reqs)
+}
+
+// answer returns the outcome of a call with the requests.
+func (u *fakeUpstream) answer(ctx context.Context, reqs []*Request) ([]*Response, error) {
+	u.calls.Add(1)
+	if u.delay > 0 {
+		select {
+		case <-time.After(u.delay):
+		case <-ctx.Done():
+			u.canceled.Add(1)
+			return nil, ctx.Err()
+		}
+	}
+
+	u.mutex.Lock()
+	err := u.err
+	u.mutex.Unlock()
+	switch {
+	case err != nil:
+		return nil, err
+	case u.silent:
+		return nil, nil
+	case u.respond != nil:
+		return u.respond(reqs)
+	}
+
+	var resps []*Response
+	for _, req := range slices.Backward(reqs) {
+		if req.IsNotification() {
+			continue
+		}
+		if u.code != 0 && (len(u.codeMethods) == 0 || slices.Contains(u.codeMethods, req.Method)) {
+			resps = append(resps, NewErrorResponse(req.ID, &Error{Code: u.code, Message: u.name}))
+			continue
+		}
+
+		id := any(req.ID)
+		if u.id != nil {
+			id = u.id
+		}
+		result := u.name
+		if u.echoMethod {
+			result = req.Method
+		}
+		resp, err := NewResponse(id, result)
+		if err != nil {
+			return nil, err
+		}
+		resps = append(resps, resp)
+	}
+	return resps, nil
+}
+
+// batchMethods returns the methods of the requests of each batch received.
+func (u *fakeUpstream) batchMethods() [][]string {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	batches := make([][]string, len(u.batches))
+	for i, batch := range u.batches {
+		for _, req := range batch {
+			batches[i] = append(batches[i], req.Method)
+		}
+	}
+	return batches
+}
+
+// batchSizes returns the number of requests of each batch received.
+func (u *fakeUpstream) batchSizes() []int {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+
+	sizes := make([]int, len(u.batches))
+	for i, batch := range u.batches {
+		sizes[i] = len(batch)
+	}
+	return sizes
+}
+
+// newEchoServer starts an httptest server that answers each request with a result holding its
+// method and the ID it was received with. Batch responses are returned in reverse order.
+func newEchoServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		reqs, isBatch, err := DecodeRequestOrBatch(body)
+		if err != nil {
+			_, _ = NewDecodeErrorResponse(body, err).WriteTo(w)
+			return
+		}
+
+		var resps []*Response
+		for _, req := range reqs {
+			if req.IsNotification() {
+				continue
+			}
+			resp, err := NewResponse(req.ID, map[string]any{
+				"method":     req.Method,
+				"upstreamId": req.ID.String(),
+				"header":     r.Header.Get("X-Test"),
+			})
+			require.NoError(t, err)
+			resps = append(resps, resp)
+		}
+		slices.Reverse(resps)
+
+		switch {
+		case len(resps) == 0:
+			w.WriteHeader(http.StatusNoContent)
+		case isBatch:
+			_, _ = WriteBatchResponse(w, resps)
+		default:
+			_, _ = resps[0].WriteTo(w)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestNewHTTPUpstream(t *testing.T) {
+	upstream, err := NewHTTPUpstream(HTTPUpstreamConfig{URL: "http://localhost:8545"})
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8545", upstream.URL())
+	assert.Same(t, http.DefaultClient, upstream.client)
+	assert.Equal(t, int64(DefaultMaxResponseSize), upstream.maxResponseSize)
+
+	for _, invalid := range []string{"", "localhost:8545", "ftp://host", "http://%zz"} {
+		_, err := NewHTTPUpstream(HTTPUpstreamConfig{URL: invalid})
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestHTTPUpstream_Call(t *testing.T) {
+	server := newEchoServer(t)
+	upstream, err := NewHTTPUpstream(HTTPUpstreamConfig{
+		URL:    server.URL,
+		Header: http.Header{"X-Test": []string{"yes"}},
+	})
+	require.NoError(t, err)
+
+	t.Run("Request", func(t *testing.T) {
+		resp, err := upstream.Call(context.Background(), NewRequestWithID("m", nil, 7))
+		require.NoError(t, err)
+		assert.Equal(t, "7", resp.IDString())
+		header, err := resp.PeekStringByPath("header")
+		require.NoError(t, err)
+		assert.Equal(t, "yes", header)
+	})
+
+	t.Run("Notification", func(t *testing.T) {
+		resp, err := upstream.Call(context.Background(), NewNotification("m", nil))
+		require.NoError(t, err)
+		assert.Nil(t, resp)
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		resps, err := upstream.CallBatch(context.Background(), []*Request{
+			NewRequestWithID("a", nil, 1),
+			NewNotification("b", nil),
+			NewRequestWithID("c", nil, 2),
+		})
+		require.NoError(t, err)
+		require.Len(t, resps, 2)
+		assert.Equal(t, "2", resps[0].IDString())
+		assert.Equal(t, "1", resps[1].IDString())
+	})
+
+	t.Run("Batch of notifications", func(t *testing.T) {
+		resps, err := upstream.CallBatch(context.Background(), []*Request{NewNotification("b", nil)})
+		require.NoError(t, err)
+		assert.Empty(t, resps)
+	})
+}
+
+func TestHTTPUpstream_Errors(t *testing.T) {
+	newUpstream := func(t *testing.T, handler http.HandlerFunc, maxSize int64) *HTTPUpstream {
+		server := httptest.NewServer(handler)
+		t.Cleanup(server.Close)
+		upstream, err := NewHTTPUpstream(HTTPUpstreamConfig{URL: server.URL, MaxResponseSize: maxSize})
+		require.NoError(t, err)
+		return upstream
+	}
+
+	t.Run("Status", func(t *testing.T) {
+		upstream := newUpstream(t, func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}, 0)
+		_, err := upstream.Call(context.Background(), NewRequest("m", nil))
+
+		var statusErr *HTTPStatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+		assert.EqualError(t, err, "upstream returned status 503 Service Unavailable")
+	})
+
+	t.Run("Retry-After", func(t *testing.T) {
+		upstream := newUpstream(t, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}, 0)
+		_, err := upstream.Call(context.Background(), NewRequest("m", nil))
+
+		var statusErr *HTTPStatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, 2*time.Second, statusErr.RetryAfter)
+	})
+
+	t.Run("Invalid body", func(t *testing.T) {
+		upstream := newUpstream(t, func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("<html>"))
+		}, 0)
+		_, err := upstream.Call(context.Background(), NewRequest("m", nil))
+		assert.ErrorContains(t, err, "failed to decode upstream response")
+		_, err = upstream.CallBatch(context.Background(), []*Request{NewRequest("m", nil)})
+		assert.ErrorContains(t, err, "failed to decode upstream response")
+	})
+
+	t.Run("Body too large", func(t *testing.T) {
+		upstream := newUpstream(t, func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0123456789"}`))
+		}, 16)
+		_, err := upstream.Call(context.Background(), NewRequestWithID("m", nil, 1))
+		assert.ErrorContains(t, err, "exceeds 16 bytes")
+	})
+
+	t.Run("Transport", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+		upstream, err := NewHTTPUpstream(HTTPUpstreamConfig{URL: server.URL})
+		require.NoError(t, err)
+		_, err = upstream.Call(context.Background(), NewRequest("m", nil))
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		upstream := newUpstream(t, http.NotFound, 0)
+		_, err := upstream.Call(context.Background(), nil)
+		assert.Error(t, err)
+		_, err = upstream.CallBatch(context.Background(), nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 5*time.Second, parseRetryAfter("5", now))
+	assert.Equal(t, 30*time.Second, parseRetryAfter("Wed, 01 Jan 2025 12:00:30 GMT", now))
+	assert.Zero(t, parseRetryAfter("Wed, 01 Jan 2025 11:00:00 GMT", now))
+	assert.Zero(t, parseRetryAfter("-5", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+	assert.Zero(t, parseRetryAfter("", now))
+}
@@ -2,6 +2,7 @@ package jsonrpc
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"io"
 	"math/rand/v2"
@@ -64,6 +65,7 @@ func formatFloat64ID(id float64) string {
 
 // RandomJSONRPCID returns a randomly generated value appropriate for a JSON-RPC ID field.
 // Returns an int64 in the range [0, 2147483647] (int32 range) for compatibility.
+// Requests created by NewRequest use the package-wide IDGenerator instead; see SetIDGenerator.
 func RandomJSONRPCID() int64 {
 	return int64(rand.IntN(2147483647)) // math.MaxInt32
 }
@@ -112,3 +114,24 @@ func readAll(reader io.Reader, chunkSize int64, expectedSize int) ([]byte, error
 
 	return result, nil
 }
+
+// appendJSONString appends s to dst as a quoted JSON string. Strings that need no escaping are
+// appended directly, others are encoded with the configured sonic API to respect its escaping
+// options.
+func appendJSONString(dst []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 0x20 || c >= 0x80 || c == '"' || c == '\\' || c == '<' || c == '>' || c == '&' {
+			encoded, err := getSonicAPI().Marshal(s)
+			if err != nil {
+				// Fall back to encoding/json, which replaces invalid UTF-8 instead of failing
+				encoded, _ = json.Marshal(s)
+			}
+			return append(dst, encoded...)
+		}
+	}
+
+	dst = append(dst, '"')
+	dst = append(dst, s...)
+	return append(dst, '"')
+}